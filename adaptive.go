@@ -0,0 +1,239 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func NewAdaptiveLimiter(c AdaptiveLimit) *AdaptiveLimiter {
+	if c.Min <= 0 {
+		c.Min = 1
+	}
+
+	if c.Max > 0 && c.Max < c.Min {
+		c.Max = c.Min
+	}
+
+	if c.Initial <= 0 {
+		c.Initial = c.Min
+	}
+
+	if c.Backoff <= 0 || c.Backoff >= 1 {
+		c.Backoff = 0.5
+	}
+
+	if c.Window <= 0 {
+		c.Window = c.LatencyThreshold
+	}
+
+	if c.Window <= 0 {
+		c.Window = 100 * time.Millisecond
+	}
+
+	l := &AdaptiveLimiter{config: c, clock: clockOrSystem(c.Clock)}
+	l.limit = l.clamp(float64(c.Initial))
+
+	return l
+}
+
+func AdaptiveConcurrencyMiddleware(l *AdaptiveLimiter) Middleware {
+	if l == nil {
+		return failingMiddleware("adaptive-concurrency", errors.New("currly: adaptive limiter must not be nil"))
+	}
+
+	return Named("adaptive-concurrency", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			if err := l.acquire(r.Context()); err != nil {
+				return nil, err
+			}
+
+			start := l.clock.Now()
+			resp, err := next.Send(r)
+
+			return releaseOnClose(resp, err, func() {
+				l.release(l.clock.Now().Sub(start), resp, err)
+			})
+		})
+	})
+}
+
+func (l *AdaptiveLimiter) Snapshot() AdaptiveSnapshot {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return AdaptiveSnapshot{
+		Limit:     int(l.limit),
+		InFlight:  l.inFlight,
+		Queued:    len(l.waiters),
+		Decreases: l.decreases,
+		Rejected:  l.rejected,
+	}
+}
+
+func (l *AdaptiveLimiter) Publish(name string) error {
+	prefix := "currly." + name + "."
+	vars := map[string]func(as AdaptiveSnapshot) interface{}{
+		"concurrency_limit":     func(as AdaptiveSnapshot) interface{} { return as.Limit },
+		"concurrency_in_flight": func(as AdaptiveSnapshot) interface{} { return as.InFlight },
+		"concurrency_queued":    func(as AdaptiveSnapshot) interface{} { return as.Queued },
+		"concurrency_decreases": func(as AdaptiveSnapshot) interface{} { return as.Decreases },
+		"concurrency_rejected":  func(as AdaptiveSnapshot) interface{} { return as.Rejected },
+	}
+
+	publishMutex.Lock()
+	defer publishMutex.Unlock()
+
+	for k := range vars {
+		if expvar.Get(prefix+k) != nil {
+			return fmt.Errorf("currly: expvar '%v' is already published", prefix+k)
+		}
+	}
+
+	for k, f := range vars {
+		f := f
+
+		expvar.Publish(prefix+k, expvar.Func(func() interface{} { return f(l.Snapshot()) }))
+	}
+
+	return nil
+}
+
+func (l *AdaptiveLimiter) acquire(ctx context.Context) error {
+	l.mutex.Lock()
+
+	if l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mutex.Unlock()
+
+		return nil
+	}
+
+	if l.config.Policy != QueueWhenSaturated {
+		l.rejected++
+		l.mutex.Unlock()
+
+		return ErrTooManyInFlight
+	}
+
+	w := make(chan struct{})
+	l.waiters = append(l.waiters, w)
+	l.mutex.Unlock()
+
+	select {
+	case <-w:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for i, c := range l.waiters {
+		if c == w {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+
+			return ctx.Err()
+		}
+	}
+
+	l.inFlight--
+	l.wake()
+
+	return ctx.Err()
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, resp *http.Response, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.inFlight--
+
+	switch {
+	case errors.Is(err, context.Canceled):
+	case l.overloaded(latency, resp, err):
+		now := l.clock.Now()
+
+		if !l.decreased.IsZero() && now.Sub(l.decreased) < l.config.Window {
+			break
+		}
+
+		l.limit = l.clamp(math.Floor(l.limit * l.config.Backoff))
+		l.decreased = now
+		l.decreases++
+	default:
+		l.limit = l.clamp(l.limit + 1/math.Floor(l.limit))
+	}
+
+	l.wake()
+}
+
+func (l *AdaptiveLimiter) overloaded(latency time.Duration, resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return true
+	}
+
+	return l.config.LatencyThreshold > 0 && latency > l.config.LatencyThreshold
+}
+
+func (l *AdaptiveLimiter) wake() {
+	for len(l.waiters) > 0 && l.inFlight < int(l.limit) {
+		w := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inFlight++
+
+		close(w)
+	}
+}
+
+func (l *AdaptiveLimiter) clamp(limit float64) float64 {
+	if limit < float64(l.config.Min) {
+		return float64(l.config.Min)
+	}
+
+	if l.config.Max > 0 && limit > float64(l.config.Max) {
+		return float64(l.config.Max)
+	}
+
+	return limit
+}
+
+type AdaptiveLimit struct {
+	Initial          int
+	Min              int
+	Max              int
+	LatencyThreshold time.Duration
+	Window           time.Duration
+	Backoff          float64
+	Policy           SaturationPolicy
+	Clock            Clock
+}
+
+type AdaptiveSnapshot struct {
+	Limit     int
+	InFlight  int
+	Queued    int
+	Decreases int64
+	Rejected  int64
+}
+
+type AdaptiveLimiter struct {
+	mutex     sync.Mutex
+	config    AdaptiveLimit
+	clock     Clock
+	limit     float64
+	inFlight  int
+	waiters   []chan struct{}
+	decreased time.Time
+	decreases int64
+	rejected  int64
+}
@@ -0,0 +1,146 @@
+package currly_test
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestAdaptiveLimiterBacksOffAndRecovers(t *testing.T) {
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	limiter := currly.NewAdaptiveLimiter(currly.AdaptiveLimit{Initial: 4, Min: 1, Max: 8, LatencyThreshold: 100 * time.Millisecond, Clock: clock})
+	status, delay := http.StatusServiceUnavailable, time.Duration(0)
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		clock.Advance(delay)
+
+		resp := okResponse(r, "")
+		resp.StatusCode = status
+
+		return resp, nil
+	}), currly.AdaptiveConcurrencyMiddleware(limiter))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	calls := func(n int) {
+		for i := 0; i < n; i++ {
+			curl(con)
+		}
+	}
+
+	cases := []struct {
+		status   int
+		delay    time.Duration
+		calls    int
+		expected int
+	}{
+		{http.StatusServiceUnavailable, 0, 1, 2},
+		{http.StatusOK, 200 * time.Millisecond, 1, 1},
+		{http.StatusTooManyRequests, 0, 3, 1},
+		{http.StatusOK, 0, 1, 2},
+		{http.StatusOK, 0, 2, 3},
+		{http.StatusOK, 0, 100, 8},
+		{http.StatusServiceUnavailable, 100 * time.Millisecond, 2, 2},
+	}
+
+	for i, c := range cases {
+		status, delay = c.status, c.delay
+
+		calls(c.calls)
+
+		if s := limiter.Snapshot(); c.expected != s.Limit || 0 != s.InFlight {
+			t.Errorf("Unexpected limiter state in case %v (expected limit: %v, actual: %+v).", i, c.expected, s)
+		}
+	}
+
+	if 4 != limiter.Snapshot().Decreases {
+		t.Errorf("Unexpected number of decreases (expected: %v, actual: %v).", 4, limiter.Snapshot().Decreases)
+	}
+}
+
+func TestAdaptiveLimiterRejectsWhenSaturated(t *testing.T) {
+	limiter := currly.NewAdaptiveLimiter(currly.AdaptiveLimit{Initial: 1, Policy: currly.FailWhenSaturated})
+	entered, done := make(chan struct{}), make(chan struct{})
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		close(entered)
+		<-done
+
+		return okResponse(r, ""), nil
+	}), currly.AdaptiveConcurrencyMiddleware(limiter))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	go curl(con)
+
+	<-entered
+
+	if _, _, err := curl(con); !errors.Is(err, currly.ErrTooManyInFlight) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrTooManyInFlight, err)
+	}
+
+	close(done)
+
+	name := fmt.Sprintf("adaptive_%v", publications.Add(1))
+
+	if err := limiter.Publish(name); err != nil {
+		t.Fatalf("Publishing the limiter gauges returned an unexpected error: %v", err)
+	}
+
+	if v := expvar.Get("currly." + name + ".concurrency_rejected"); v == nil || "1" != v.String() {
+		t.Errorf("Unexpected rejection gauge: %v.", v)
+	}
+}
+
+func TestAdaptiveLimiterHoldsSlotsUntilBodiesAreClosed(t *testing.T) {
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	limiter := currly.NewAdaptiveLimiter(currly.AdaptiveLimit{Initial: 2, LatencyThreshold: 100 * time.Millisecond, Clock: clock})
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, "ok"), nil
+	}), currly.AdaptiveConcurrencyMiddleware(limiter))
+	r, _ := http.NewRequest(http.MethodGet, "https://localhost", nil)
+	resp, err := con.Send(r)
+
+	if err != nil {
+		t.Fatalf("Sending the request returned an unexpected error: %v", err)
+	}
+
+	if s := limiter.Snapshot(); 1 != s.InFlight {
+		t.Errorf("Unexpected number of requests in flight (expected: %v, actual: %v).", 1, s.InFlight)
+	}
+
+	clock.Advance(200 * time.Millisecond)
+	resp.Body.Close()
+
+	if s := limiter.Snapshot(); 0 != s.InFlight || 1 != s.Decreases {
+		t.Errorf("Unexpected limiter state after closing the body: %+v.", s)
+	}
+}
+
+func TestAdaptiveConcurrencyMiddlewareRejectsMissingLimiters(t *testing.T) {
+	calls := 0
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+
+		return okResponse(r, ""), nil
+	}), currly.AdaptiveConcurrencyMiddleware(nil))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err := curl(con); err == nil || 0 != calls {
+		t.Errorf("Expected an error for a missing limiter (calls: %v, error: %v).", calls, err)
+	}
+}
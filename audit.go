@@ -0,0 +1,173 @@
+package currly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+func AuditMiddleware(a Audit) Middleware {
+	if a.Sink == nil {
+		return failingMiddleware("audit", errors.New("currly: audit sink must not be nil"))
+	}
+
+	clock := clockOrSystem(a.Clock)
+
+	return Named("audit", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			start := clock.Now()
+			resp, err := next.Send(r)
+			ci := callInfoFrom(r.Context())
+			p := RedactionPolicyFromContext(r.Context())
+			rec := AuditRecord{
+				Time:        start,
+				Template:    ci.templateName,
+				Method:      r.Method,
+				URL:         p.URL(r.URL),
+				URLTemplate: ci.urlTemplate,
+				Latency:     clock.Now().Sub(start),
+				Attempt:     ci.attempt,
+				Labels:      copyLabels(ci.labels),
+			}
+
+			if id, ok := CorrelationIDFromContext(r.Context()); ok {
+				rec.CorrelationID = id
+			}
+
+			if err != nil {
+				rec.Error = redactError(err, p, r.URL)
+			} else {
+				rec.Status = resp.StatusCode
+			}
+
+			if serr := a.Sink.Record(rec); serr != nil && a.FailClosed {
+				if err == nil {
+					DrainBody(resp.Body)
+				}
+
+				return nil, fmt.Errorf("currly: recording the audit trail failed: %w", serr)
+			}
+
+			return resp, err
+		})
+	})
+}
+
+func WriterAuditSink(w io.Writer) AuditSink {
+	return &writerAuditSink{w: w}
+}
+
+func FileAuditSink(path string) (*FileAudit, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileAudit{AuditSink: WriterAuditSink(f), f: f}, nil
+}
+
+func redactError(err error, p RedactionPolicy, u *url.URL) string {
+	msg := err.Error()
+	raw := map[string]string{u.String(): p.URL(u)}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if ue, ok := e.(*url.Error); ok {
+			if pu, perr := url.Parse(ue.URL); perr == nil {
+				raw[ue.URL] = p.URL(pu)
+			} else {
+				raw[ue.URL] = p.URL(u)
+			}
+		}
+	}
+
+	for k, v := range raw {
+		if len(k) > 0 {
+			msg = strings.ReplaceAll(msg, k, v)
+		}
+	}
+
+	return msg
+}
+
+func (f AuditSinkFunc) Record(r AuditRecord) error {
+	return f(r)
+}
+
+func (s *writerAuditSink) Record(r AuditRecord) error {
+	bs, err := json.Marshal(r)
+
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.w.Write(append(bs, '\n'))
+
+	return err
+}
+
+func (fa *FileAudit) Close() error {
+	return fa.f.Close()
+}
+
+func (r AuditRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time          time.Time         `json:"time"`
+		Template      string            `json:"template,omitempty"`
+		Method        string            `json:"method"`
+		URL           string            `json:"url"`
+		URLTemplate   string            `json:"url_template,omitempty"`
+		Status        int               `json:"status,omitempty"`
+		LatencyMillis float64           `json:"latency_ms"`
+		Attempt       int               `json:"attempt"`
+		Labels        map[string]string `json:"labels,omitempty"`
+		CorrelationID string            `json:"correlation_id,omitempty"`
+		Error         string            `json:"error,omitempty"`
+	}{r.Time, r.Template, r.Method, r.URL, r.URLTemplate, r.Status, milliseconds(r.Latency), r.Attempt, r.Labels, r.CorrelationID, r.Error})
+}
+
+type Audit struct {
+	Sink       AuditSink
+	Clock      Clock
+	FailClosed bool
+}
+
+type AuditSink interface {
+	Record(r AuditRecord) error
+}
+
+type AuditSinkFunc func(r AuditRecord) error
+
+type AuditRecord struct {
+	Time          time.Time
+	Template      string
+	Method        string
+	URL           string
+	URLTemplate   string
+	Status        int
+	Latency       time.Duration
+	Attempt       int
+	Labels        map[string]string
+	CorrelationID string
+	Error         string
+}
+
+type FileAudit struct {
+	AuditSink
+	f *os.File
+}
+
+type writerAuditSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
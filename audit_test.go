@@ -0,0 +1,163 @@
+package currly_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestAuditMiddlewareRecordsRedactedCalls(t *testing.T) {
+	buf := new(bytes.Buffer)
+	clock := currlytest.NewFakeClock(time.Unix(1700000000, 0).UTC())
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		clock.Advance(250 * time.Millisecond)
+
+		resp := okResponse(r, "")
+		resp.StatusCode = http.StatusCreated
+
+		return resp, nil
+	}), currly.AuditMiddleware(currly.Audit{Sink: currly.WriterAuditSink(buf), Clock: clock}))
+	curl, err := currly.Builder().POST().HTTPS().Localhost().PathSegment("payments").QueryParam("token").
+		Name("payments.create").
+		ResultExtractor(currly.PlainStringExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con, currly.QueryArg("token", "s3cr3t"), currly.LabelArg("tenant", "acme")); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	var rec map[string]interface{}
+
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("The audit record should be valid JSON: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"time":         "2023-11-14T22:13:20Z",
+		"template":     "payments.create",
+		"method":       http.MethodPost,
+		"status":       float64(http.StatusCreated),
+		"latency_ms":   float64(250),
+		"url_template": "https://localhost/payments?token={token}",
+	}
+
+	for k, v := range expected {
+		if v != rec[k] {
+			t.Errorf("Unexpected audit attribute '%v' (expected: %v, actual: %v).", k, v, rec[k])
+		}
+	}
+
+	if url, _ := rec["url"].(string); strings.Contains(url, "s3cr3t") {
+		t.Errorf("The audited URL should be redacted: %v.", url)
+	}
+
+	if labels, _ := rec["labels"].(map[string]interface{}); "acme" != labels["tenant"] {
+		t.Errorf("Unexpected audited labels: %v.", rec["labels"])
+	}
+}
+
+func TestAuditMiddlewareFailsClosed(t *testing.T) {
+	errSink := errors.New("sink unavailable")
+	sink := currly.AuditSinkFunc(func(r currly.AuditRecord) error { return errSink })
+	ok := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err := curl(currly.Wrap(ok, currly.AuditMiddleware(currly.Audit{Sink: sink}))); err != nil {
+		t.Errorf("A failing sink should not fail the call by default: %v", err)
+	}
+
+	if _, _, err := curl(currly.Wrap(ok, currly.AuditMiddleware(currly.Audit{Sink: sink, FailClosed: true}))); !errors.Is(err, errSink) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", errSink, err)
+	}
+}
+
+func TestFileAuditSinkAppendsRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for i := 0; i < 2; i++ {
+		sink, err := currly.FileAuditSink(path)
+
+		if err != nil {
+			t.Fatalf("Opening the audit file returned an unexpected error: %v", err)
+		}
+
+		if err := sink.Record(currly.AuditRecord{Method: http.MethodGet, URL: "https://localhost"}); err != nil {
+			t.Fatalf("Recording the audit record returned an unexpected error: %v", err)
+		}
+
+		sink.Close()
+	}
+
+	bs, _ := ioutil.ReadFile(path)
+
+	if 2 != strings.Count(string(bs), "\n") {
+		t.Errorf("Unexpected audit file content: %s", bs)
+	}
+
+	if fi, err := os.Stat(path); err == nil && 0600 != fi.Mode().Perm() {
+		t.Errorf("Unexpected audit file mode (expected: %v, actual: %v).", os.FileMode(0600), fi.Mode().Perm())
+	}
+}
+
+func TestAuditMiddlewareRedactsTransportErrors(t *testing.T) {
+	var rec currly.AuditRecord
+
+	sink := currly.AuditSinkFunc(func(r currly.AuditRecord) error {
+		rec = r
+
+		return nil
+	})
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("dial failed: %w", &url.Error{Op: "Get", URL: r.URL.String(), Err: errors.New("connection refused")})
+	}), currly.AuditMiddleware(currly.Audit{Sink: sink}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathSegment("payments").QueryParam("token").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con, currly.QueryArg("token", "s3cr3t"))
+
+	if 0 == len(rec.Error) || strings.Contains(rec.Error, "s3cr3t") {
+		t.Errorf("The audited error should be redacted: %v.", rec.Error)
+	}
+}
+
+func TestAuditMiddlewareRejectsMissingSinks(t *testing.T) {
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("A misconfigured audit middleware should not send requests.")
+
+		return okResponse(r, ""), nil
+	}), currly.AuditMiddleware(currly.Audit{}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err := curl(con); err == nil {
+		t.Errorf("Calling through an audit middleware without a sink should fail.")
+	}
+}
@@ -0,0 +1,119 @@
+package currly
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func CredentialsArg(username, password string) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		ct.credentials = credentials{username, password}
+
+		return nil
+	})
+}
+
+func BearerTokenArg(token string) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		ct.header.Set("Authorization", "Bearer "+token)
+
+		return nil
+	})
+}
+
+func ExpiringArg(a Arg, expiresAt time.Time) Arg {
+	return expiringArg{Arg: a, expiresAt: expiresAt}
+}
+
+type AuthRefreshFunc func(ctx context.Context) (Arg, error)
+
+type expiringArg struct {
+	Arg
+	expiresAt time.Time
+}
+
+type authRefresher struct {
+	mutex   sync.Mutex
+	refresh AuthRefreshFunc
+	arg     Arg
+}
+
+func newAuthRefresher(refresh AuthRefreshFunc) *authRefresher {
+	if refresh == nil {
+		return nil
+	}
+
+	return &authRefresher{refresh: refresh}
+}
+
+func (ar *authRefresher) prepend(args []Arg) []Arg {
+	if ar == nil {
+		return args
+	}
+
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	if ar.arg == nil {
+		return args
+	}
+
+	return append([]Arg{ar.arg}, args...)
+}
+
+func (ar *authRefresher) expired(now time.Time) bool {
+	if ar == nil {
+		return false
+	}
+
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	ea, ok := ar.arg.(expiringArg)
+
+	return ok && !now.Before(ea.expiresAt)
+}
+
+func (ar *authRefresher) renew(ctx context.Context) error {
+	a, err := ar.refresh(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	ar.arg = a
+
+	return nil
+}
+
+func invoke(ct curlTemplate, ar *authRefresher, con Connector, args []Arg) (int, interface{}, map[string]string, error) {
+	cct := complete(ct, ar.prepend(args))
+
+	if ar.expired(clockOrSystem(ct.clock).Now()) {
+		if err := ar.renew(baseContext(cct)); err != nil {
+			return 0, nil, cct.labels, err
+		}
+
+		cct = complete(ct, ar.prepend(args))
+	}
+
+	sc, ret, err := call(cct, con)
+
+	if ar == nil || sc != http.StatusUnauthorized {
+		return sc, ret, cct.labels, err
+	}
+
+	if err := ar.renew(baseContext(cct)); err != nil {
+		return sc, ret, cct.labels, err
+	}
+
+	cct = complete(ct, ar.prepend(args))
+	sc, ret, err = call(cct, con)
+
+	return sc, ret, cct.labels, err
+}
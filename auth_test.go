@@ -0,0 +1,94 @@
+package currly_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestOnUnauthorizedRefreshesAuthAndRetriesOnce(t *testing.T) {
+	var calls, refreshes int
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		resp := okResponse(r, "")
+
+		if "Bearer fresh" != r.Header.Get("Authorization") {
+			resp.StatusCode = http.StatusUnauthorized
+		}
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		OnUnauthorized(func(ctx context.Context) (currly.Arg, error) {
+			refreshes++
+
+			return currly.BearerTokenArg("fresh"), nil
+		}).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		sc, _, err := curl(con)
+
+		if err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if http.StatusOK != sc {
+			t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusOK, sc)
+		}
+	}
+
+	if 1 != refreshes || 3 != calls {
+		t.Errorf("Unexpected number of refreshes and calls (expected: 1 and 3, actual: %v and %v).", refreshes, calls)
+	}
+}
+
+func TestOnUnauthorizedRenewsExpiredTokensBeforeCalling(t *testing.T) {
+	var calls, refreshes int
+
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		resp := okResponse(r, "")
+
+		if fmt.Sprintf("Bearer token-%v", refreshes) != r.Header.Get("Authorization") {
+			resp.StatusCode = http.StatusUnauthorized
+		}
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		OnUnauthorized(func(ctx context.Context) (currly.Arg, error) {
+			refreshes++
+			token := currly.BearerTokenArg(fmt.Sprintf("token-%v", refreshes))
+
+			return currly.ExpiringArg(token, clock.Now().Add(time.Hour)), nil
+		}).
+		Clock(clock).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con)
+	clock.Advance(time.Hour)
+
+	if sc, _, _ := curl(con); http.StatusOK != sc {
+		t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusOK, sc)
+	}
+
+	if 2 != refreshes || 3 != calls {
+		t.Errorf("Unexpected number of refreshes and calls (expected: 2 and 3, actual: %v and %v).", refreshes, calls)
+	}
+}
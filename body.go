@@ -0,0 +1,236 @@
+package currly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+const (
+	ReplaceDefaultBody BodyMergeStrategy = iota
+	ShallowMergeBody
+	DeepMergeBody
+)
+
+var ErrBodyConsumed = errors.New("currly: request body has already been consumed by a previous call")
+
+func BodyArg(r io.Reader) Arg {
+	cb := &consumableBody{r: r}
+
+	return argFunc(func(ct *curlTemplate) error {
+		if r == nil {
+			return errors.New("currly: body reader must not be nil")
+		}
+
+		if cb.consumed.Load() {
+			return ErrBodyConsumed
+		}
+
+		ct.body = cb
+		ct.contentLength = 0
+
+		return nil
+	})
+}
+
+func FileBodyArg(path string) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		f, err := os.Open(path)
+
+		if err != nil {
+			return err
+		}
+
+		fi, err := f.Stat()
+
+		if err != nil {
+			f.Close()
+
+			return err
+		}
+
+		if ct.header == nil {
+			ct.header = make(http.Header)
+		}
+
+		var r io.Reader = f
+
+		if len(ct.header.Get("Content-Type")) == 0 {
+			contentType := mime.TypeByExtension(filepath.Ext(path))
+
+			if len(contentType) == 0 {
+				buf := make([]byte, 512)
+				n, err := io.ReadFull(f, buf)
+
+				if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+					f.Close()
+
+					return err
+				}
+
+				contentType = http.DetectContentType(buf[:n])
+				r = io.MultiReader(bytes.NewReader(buf[:n]), f)
+			}
+
+			ct.header.Set("Content-Type", contentType)
+		}
+
+		if fi.Mode().IsRegular() && fi.Size() == 0 {
+			f.Close()
+
+			ct.body = http.NoBody
+			ct.contentLength = 0
+
+			return nil
+		}
+
+		ct.body = readCloser{r, f}
+		ct.contentLength = fi.Size()
+
+		return nil
+	})
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type BodyFactory func() (interface{}, error)
+
+type consumableBody struct {
+	r        io.Reader
+	consumed atomic.Bool
+}
+
+type BodyMergeStrategy int
+
+type replayableBody interface {
+	replay() io.ReadCloser
+}
+
+type jsonBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func newJSONBody(data []byte) *jsonBody {
+	return &jsonBody{bytes.NewReader(data), data}
+}
+
+func (jb *jsonBody) Close() error {
+	return nil
+}
+
+func (jb *jsonBody) replay() io.ReadCloser {
+	return newJSONBody(jb.data)
+}
+
+func (cb *consumableBody) Read(p []byte) (int, error) {
+	cb.consumed.Store(true)
+
+	return cb.r.Read(p)
+}
+
+func (cb *consumableBody) Close() error {
+	cb.consumed.Store(true)
+
+	if c, ok := cb.r.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+func applyDefaultBody(ct *curlTemplate) error {
+	jb, ok := ct.body.(*jsonBody)
+
+	if ct.body != nil && (!ok || ct.bodyMerge == ReplaceDefaultBody) {
+		return nil
+	}
+
+	v, err := ct.defaultBody()
+
+	if err != nil {
+		return err
+	}
+
+	bs, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		if bs, err = mergeJSON(bs, jb.data, ct.bodyMerge == DeepMergeBody); err != nil {
+			return err
+		}
+	} else {
+		ct.header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	ct.body = newJSONBody(bs)
+	ct.contentLength = int64(len(bs))
+
+	return nil
+}
+
+func mergeJSON(base, override []byte, deep bool) ([]byte, error) {
+	b, err := decodeJSON(base)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := decodeJSON(override)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bm, ok := b.(map[string]interface{})
+	om, isObject := o.(map[string]interface{})
+
+	if !ok || !isObject {
+		return override, nil
+	}
+
+	return json.Marshal(mergeObjects(bm, om, deep))
+}
+
+func mergeObjects(b, o map[string]interface{}, deep bool) map[string]interface{} {
+	for k, v := range o {
+		bv, isObject := b[k].(map[string]interface{})
+		ov, ok := v.(map[string]interface{})
+
+		switch {
+		case v == nil:
+			delete(b, k)
+		case deep && isObject && ok:
+			b[k] = mergeObjects(bv, ov, deep)
+		default:
+			b[k] = v
+		}
+	}
+
+	return b
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	var v interface{}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,288 @@
+package currly_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestFileBodyArgStreamsFileWithDetectedContentType(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name        string
+		content     string
+		header      http.Header
+		contentType string
+	}{
+		{"data.json", `{"id": 42}`, nil, "application/json"},
+		{"page", "<html><body>hi</body></html>", nil, "text/html; charset=utf-8"},
+		{"page", "<html><body>hi</body></html>", http.Header{"Content-Type": {"text/plain"}}, "text/plain"},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name)
+
+		if err := ioutil.WriteFile(path, []byte(c.content), 0600); err != nil {
+			t.Fatalf("Writing the body file returned an unexpected error: %v", err)
+		}
+
+		var req *http.Request
+		var body []byte
+
+		con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+			req = r
+			body, _ = ioutil.ReadAll(r.Body)
+
+			return okResponse(r, ""), nil
+		})
+		curl, err := currly.Builder().POST().HTTPS().Localhost().Header(c.header).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		if _, _, err = curl(con, currly.FileBodyArg(path)); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if c.contentType != req.Header.Get("Content-Type") {
+			t.Errorf("Unexpected content type (expected: %v, actual: %v).", c.contentType, req.Header.Get("Content-Type"))
+		}
+
+		if int64(len(c.content)) != req.ContentLength {
+			t.Errorf("Unexpected content length (expected: %v, actual: %v).", len(c.content), req.ContentLength)
+		}
+
+		if c.content != string(body) {
+			t.Errorf("Unexpected body (expected: %v, actual: %v).", c.content, string(body))
+		}
+	}
+}
+
+func TestFileBodyArgSendsEmptyFilesWithoutChunking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("Writing the body file returned an unexpected error: %v", err)
+	}
+
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con, currly.FileBodyArg(path)); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if http.NoBody != req.Body || 0 != req.ContentLength {
+		t.Errorf("An empty file should be sent as an empty body with a known length.")
+	}
+}
+
+func TestFileBodyArgClosesFileWhenCallFailsBeforeSending(t *testing.T) {
+	fds, err := ioutil.ReadDir("/proc/self/fd")
+
+	if err != nil {
+		t.Skip("Open file descriptors cannot be counted on this platform.")
+	}
+
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	if err := ioutil.WriteFile(path, []byte(`{"id": 42}`), 0600); err != nil {
+		t.Fatalf("Writing the body file returned an unexpected error: %v", err)
+	}
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().
+		OnBeforeSend(func(r *http.Request) error { return errors.New("rejected") }).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		curl(con, currly.FileBodyArg(path), currly.PathArg("missing", "x"))
+		curl(con, currly.FileBodyArg(path))
+	}
+
+	after, _ := ioutil.ReadDir("/proc/self/fd")
+
+	if len(after) > len(fds)+5 {
+		t.Errorf("Unexpected number of open file descriptors (before: %v, after: %v).", len(fds), len(after))
+	}
+}
+
+func TestDefaultBodyIsMergedWithCallBody(t *testing.T) {
+	var body string
+	var contentLength int64
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		body = string(bs)
+		contentLength = r.ContentLength
+
+		return okResponse(r, ""), nil
+	})
+	defaultBody := map[string]interface{}{
+		"kind":    "report",
+		"options": map[string]interface{}{"format": "pdf", "pages": 10},
+		"draft":   true,
+	}
+	cases := []struct {
+		strategy currly.BodyMergeStrategy
+		args     []currly.Arg
+		expected string
+	}{
+		{currly.ShallowMergeBody, nil, `{"draft":true,"kind":"report","options":{"format":"pdf","pages":10}}`},
+		{currly.ReplaceDefaultBody, []currly.Arg{currly.JSONBodyArg(map[string]int{"id": 1})}, `{"id":1}`},
+		{currly.ShallowMergeBody, []currly.Arg{currly.JSONBodyArg(map[string]interface{}{"options": map[string]int{"pages": 2}, "draft": nil})}, `{"kind":"report","options":{"pages":2}}`},
+		{currly.DeepMergeBody, []currly.Arg{currly.JSONBodyArg(map[string]interface{}{"options": map[string]int{"pages": 2}})}, `{"draft":true,"kind":"report","options":{"format":"pdf","pages":2}}`},
+		{currly.DeepMergeBody, []currly.Arg{currly.JSONBodyArg([]int{1, 2})}, `[1,2]`},
+	}
+
+	for _, c := range cases {
+		curl, err := currly.Builder().POST().HTTPS().Localhost().DefaultBody(defaultBody, c.strategy).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		if _, _, err = curl(con, c.args...); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if c.expected != body {
+			t.Errorf("Unexpected body (expected: %v, actual: %v).", c.expected, body)
+		}
+
+		if c.args == nil && int64(len(body)) != contentLength {
+			t.Errorf("Unexpected content length (expected: %v, actual: %v).", len(body), contentLength)
+		}
+	}
+
+	if 10 != defaultBody["options"].(map[string]interface{})["pages"] {
+		t.Errorf("The default body was modified by a merge.")
+	}
+}
+
+func TestDefaultBodyFactoryIsCalledPerCall(t *testing.T) {
+	calls := 0
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().
+		DefaultBodyFactory(func() (interface{}, error) {
+			calls++
+
+			if calls > 1 {
+				return nil, errors.New("factory failed")
+			}
+
+			return map[string]int{"n": calls}, nil
+		}, currly.ShallowMergeBody).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err == nil || "factory failed" != err.Error() {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", "factory failed", err)
+	}
+
+	if _, err = currly.Builder().POST().HTTPS().Localhost().DefaultBodyFactory(nil, currly.DeepMergeBody).Build(); err == nil {
+		t.Errorf("Expected an error for a nil body factory.")
+	}
+}
+
+func TestJSONBodyArgSetsContentLengthAndIsReplayable(t *testing.T) {
+	var bodies []string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		bs, _ := ioutil.ReadAll(r.Body)
+
+		if int64(len(bs)) != r.ContentLength || r.GetBody == nil {
+			t.Errorf("Unexpected request body (content length: %v, body length: %v, replayable: %v).", r.ContentLength, len(bs), r.GetBody != nil)
+		}
+
+		replayed, _ := r.GetBody()
+		rs, _ := ioutil.ReadAll(replayed)
+		bodies = append(bodies, string(bs), string(rs))
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	arg := currly.JSONBodyArg(map[string]string{"name": "currly"})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err = curl(con, arg); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+	}
+
+	for _, b := range bodies {
+		if `{"name":"currly"}` != b {
+			t.Errorf("Unexpected body (expected: %v, actual: %v).", `{"name":"currly"}`, b)
+		}
+	}
+}
+
+func TestBodyArgFailsWhenReusedAfterConsumption(t *testing.T) {
+	var bodies []string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(bs))
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	arg := currly.BodyArg(strings.NewReader("payload"))
+
+	if _, _, err = curl(con, arg); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con, arg); !errors.Is(err, currly.ErrBodyConsumed) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrBodyConsumed, err)
+	}
+
+	if 1 != len(bodies) || "payload" != bodies[0] {
+		t.Errorf("Unexpected bodies sent: %q.", bodies)
+	}
+
+	if _, _, err = curl(con, currly.BodyArg(nil)); err == nil {
+		t.Errorf("Expected an error for a nil body reader.")
+	}
+}
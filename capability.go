@@ -0,0 +1,120 @@
+package currly
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+var (
+	ErrUpgradeNotSupported  = errors.New("currly: connector does not support protocol upgrades")
+	ErrTrailersNotSupported = errors.New("currly: connector does not support trailers")
+)
+
+func Upgrade(con Connector, r *http.Request, protocol string) (io.ReadWriteCloser, *http.Response, error) {
+	u, ok := con.(Upgrader)
+
+	if !ok {
+		return nil, nil, ErrUpgradeNotSupported
+	}
+
+	return u.Upgrade(r, protocol)
+}
+
+func ReadTrailer(con Connector, resp *http.Response) (http.Header, error) {
+	tr, ok := con.(TrailerReader)
+
+	if !ok {
+		return nil, ErrTrailersNotSupported
+	}
+
+	return tr.ReadTrailer(resp)
+}
+
+type Upgrader interface {
+	Connector
+	Upgrade(r *http.Request, protocol string) (io.ReadWriteCloser, *http.Response, error)
+}
+
+type TrailerReader interface {
+	Connector
+	ReadTrailer(resp *http.Response) (http.Header, error)
+}
+
+type upgradedConn struct {
+	io.Reader
+	io.Writer
+	io.Closer
+}
+
+func (cc *clientConnector) Upgrade(r *http.Request, protocol string) (io.ReadWriteCloser, *http.Response, error) {
+	return upgrade(cc, r, protocol)
+}
+
+func (cc *clientConnector) ReadTrailer(resp *http.Response) (http.Header, error) {
+	if resp == nil || resp.Body == nil {
+		return nil, errors.New("currly: response has no body")
+	}
+
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		return nil, err
+	}
+
+	return resp.Trailer, nil
+}
+
+func (cc *chainConnector) Upgrade(r *http.Request, protocol string) (io.ReadWriteCloser, *http.Response, error) {
+	if _, ok := cc.next.(Upgrader); !ok {
+		return nil, nil, ErrUpgradeNotSupported
+	}
+
+	return upgrade(cc.Connector, r, protocol)
+}
+
+func (cc *chainConnector) ReadTrailer(resp *http.Response) (http.Header, error) {
+	return ReadTrailer(cc.next, resp)
+}
+
+func upgrade(con Connector, r *http.Request, protocol string) (io.ReadWriteCloser, *http.Response, error) {
+	if len(protocol) == 0 {
+		return nil, nil, errors.New("currly: upgrade protocol must not be empty")
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", protocol)
+
+	resp, err := con.Send(r)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, resp, fmt.Errorf("currly: upgrade to '%v' was refused with status %v", protocol, resp.StatusCode)
+	}
+
+	body := resp.Body
+
+	for {
+		rb, ok := body.(*releasingBody)
+
+		if !ok {
+			break
+		}
+
+		body = rb.ReadCloser
+	}
+
+	w, ok := body.(io.Writer)
+
+	if !ok {
+		resp.Body.Close()
+
+		return nil, resp, ErrUpgradeNotSupported
+	}
+
+	return &upgradedConn{Reader: resp.Body, Writer: w, Closer: resp.Body}, resp, nil
+}
@@ -0,0 +1,97 @@
+package currly_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestConnectorUpgradesProtocols(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "echo" != r.Header.Get("Upgrade") || "1" != r.Header.Get("X-Test") {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		conn, rw, err := http.NewResponseController(w).Hijack()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+
+		line, _ := rw.ReadString('\n')
+		rw.WriteString(line)
+		rw.Flush()
+	}))
+	defer srv.Close()
+
+	con := currly.Wrap(currly.DefaultConnector(), currly.DefaultHeaderMiddleware(http.Header{"X-Test": {"1"}}))
+	r, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	rwc, resp, err := currly.Upgrade(con, r, "echo")
+
+	if err != nil {
+		t.Fatalf("Upgrading the connection returned an unexpected error: %v", err)
+	}
+
+	defer rwc.Close()
+
+	if http.StatusSwitchingProtocols != resp.StatusCode {
+		t.Errorf("Unexpected status code (expected: %v, actual: %v).", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+
+	if _, err := io.WriteString(rwc, "ping\n"); err != nil {
+		t.Fatalf("Writing to the upgraded connection returned an unexpected error: %v", err)
+	}
+
+	if line, err := bufio.NewReader(rwc).ReadString('\n'); err != nil || "ping\n" != line {
+		t.Errorf("Unexpected echo (expected: %q, actual: %q, error: %v).", "ping\n", line, err)
+	}
+
+	if _, _, err := currly.Upgrade(connectorFunc(nil), r, "echo"); !errors.Is(err, currly.ErrUpgradeNotSupported) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrUpgradeNotSupported, err)
+	}
+}
+
+func TestConnectorReadsTrailers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Write([]byte("payload"))
+		w.Header().Set("X-Checksum", "abc123")
+	}))
+	defer srv.Close()
+
+	con := currly.DefaultConnector()
+	r, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := con.Send(r)
+
+	if err != nil {
+		t.Fatalf("Sending the request returned an unexpected error: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	trailer, err := currly.ReadTrailer(con, resp)
+
+	if err != nil {
+		t.Fatalf("Reading the trailers returned an unexpected error: %v", err)
+	}
+
+	if "abc123" != trailer.Get("X-Checksum") {
+		t.Errorf("Unexpected trailer (expected: %v, actual: %v).", "abc123", trailer.Get("X-Checksum"))
+	}
+
+	if _, err := currly.ReadTrailer(connectorFunc(nil), resp); !errors.Is(err, currly.ErrTrailersNotSupported) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrTrailersNotSupported, err)
+	}
+}
@@ -0,0 +1,101 @@
+package currly
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+type clockContext struct {
+	parent   context.Context
+	deadline time.Time
+	done     chan struct{}
+	once     sync.Once
+	mutex    sync.Mutex
+	err      error
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	if c.deadline.IsZero() {
+		return c.parent.Deadline()
+	}
+
+	if d, ok := c.parent.Deadline(); ok && d.Before(c.deadline) {
+		return d, true
+	}
+
+	return c.deadline, true
+}
+
+func (c *clockContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *clockContext) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
+
+func (c *clockContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+func (c *clockContext) cancel(err error) {
+	c.once.Do(func() {
+		c.mutex.Lock()
+		c.err = err
+		c.mutex.Unlock()
+
+		close(c.done)
+	})
+}
+
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+
+	return c
+}
+
+func withTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if c == nil {
+		return context.WithTimeout(ctx, d)
+	}
+
+	cc := &clockContext{parent: ctx, deadline: c.Now().Add(d), done: make(chan struct{})}
+	expired := c.After(d)
+
+	go func() {
+		select {
+		case <-expired:
+			cc.cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+			cc.cancel(ctx.Err())
+		case <-cc.done:
+		}
+	}()
+
+	return cc, func() { cc.cancel(context.Canceled) }
+}
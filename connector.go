@@ -0,0 +1,155 @@
+package currly
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	AnyAddressFamily AddressFamily = iota
+	IPv4Only
+	IPv6Only
+)
+
+func ConfiguredConnector(o ConnectorOptions) Connector {
+	con := ClientConnector(&http.Client{Transport: o.transport(), Timeout: o.Timeout})
+
+	if o.Policies != nil {
+		return Wrap(con, o.Policies.Middleware())
+	}
+
+	return con
+}
+
+func WithTimeout(d time.Duration) ConnectorOption {
+	return func(o *ConnectorOptions) {
+		o.Timeout = d
+	}
+}
+
+func WithProxy(u *url.URL) ConnectorOption {
+	return func(o *ConnectorOptions) {
+		o.Proxy = http.ProxyURL(u)
+	}
+}
+
+func WithTLSConfig(c *tls.Config) ConnectorOption {
+	return func(o *ConnectorOptions) {
+		o.TLSConfig = c
+	}
+}
+
+func WithPoolSize(maxIdleConns, maxIdleConnsPerHost, maxConnsPerHost int) ConnectorOption {
+	return func(o *ConnectorOptions) {
+		o.MaxIdleConns = maxIdleConns
+		o.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		o.MaxConnsPerHost = maxConnsPerHost
+	}
+}
+
+func WithHTTP2(enabled bool) ConnectorOption {
+	return func(o *ConnectorOptions) {
+		o.DisableHTTP2 = !enabled
+	}
+}
+
+func WithPolicies(pr *PolicyRegistry) ConnectorOption {
+	return func(o *ConnectorOptions) {
+		o.Policies = pr
+	}
+}
+
+type AddressFamily int
+
+type ConnectorOption func(o *ConnectorOptions)
+
+type ConnectorOptions struct {
+	AddressFamily       AddressFamily
+	FallbackDelay       time.Duration
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+	DisableHTTP2        bool
+	Timeout             time.Duration
+	Proxy               func(r *http.Request) (*url.URL, error)
+	TLSConfig           *tls.Config
+	Policies            *PolicyRegistry
+}
+
+func (o ConnectorOptions) transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	d := &net.Dialer{
+		Timeout:       30 * time.Second,
+		KeepAlive:     30 * time.Second,
+		FallbackDelay: o.FallbackDelay,
+	}
+	af := o.AddressFamily
+
+	if o.DialTimeout > 0 {
+		d.Timeout = o.DialTimeout
+	}
+
+	if o.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = o.TLSHandshakeTimeout
+	}
+
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+
+	if o.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+
+	if o.Proxy != nil {
+		t.Proxy = o.Proxy
+	}
+
+	if o.TLSConfig != nil {
+		t.TLSClientConfig = o.TLSConfig.Clone()
+	}
+
+	if o.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+
+	t.DisableKeepAlives = o.DisableKeepAlives
+
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return d.DialContext(ctx, af.network(network), addr)
+	}
+
+	return t
+}
+
+func (af AddressFamily) network(network string) string {
+	if network != "tcp" {
+		return network
+	}
+
+	switch af {
+	case IPv4Only:
+		return "tcp4"
+	case IPv6Only:
+		return "tcp6"
+	default:
+		return network
+	}
+}
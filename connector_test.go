@@ -0,0 +1,256 @@
+package currly_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestConfiguredConnectorHonorsAddressFamily(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().GET().HTTP().Localhost().Port(uint(port)).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	con := currly.ConfiguredConnector(currly.ConnectorOptions{AddressFamily: currly.IPv4Only})
+	sc, _, err := curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if http.StatusOK != sc {
+		t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusOK, sc)
+	}
+
+	con = currly.ConfiguredConnector(currly.ConnectorOptions{AddressFamily: currly.IPv6Only})
+	_, _, err = curl(con)
+
+	if err == nil {
+		t.Errorf("Dialing an IPv4-only server over IPv6 should fail.")
+	}
+}
+
+func TestConfiguredConnectorDisablesKeepAlives(t *testing.T) {
+	var closed bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		closed = r.Close
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().GET().HTTP().Host(u.Hostname()).Port(uint(port)).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	con := currly.ConfiguredConnector(currly.ConnectorOptions{DisableKeepAlives: true, MaxIdleConnsPerHost: 4})
+	_, _, err = curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if !closed {
+		t.Errorf("Requests should ask the server to close the connection when keep-alives are disabled.")
+	}
+}
+
+func TestDefaultConnectorAppliesOptions(t *testing.T) {
+	var proto atomic.Int32
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+
+			return
+		}
+
+		proto.Store(int32(r.ProtoMajor))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().GET().HTTPS().Host(u.Hostname()).Port(uint(port)).PathParam("path").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	cases := []struct {
+		opts  []currly.ConnectorOption
+		path  string
+		proto int
+		fails bool
+	}{
+		{nil, "fast", 2, false},
+		{[]currly.ConnectorOption{currly.WithHTTP2(false)}, "fast", 1, false},
+		{[]currly.ConnectorOption{currly.WithTLSConfig(&tls.Config{RootCAs: pool})}, "fast", 2, false},
+		{[]currly.ConnectorOption{currly.WithTLSConfig(&tls.Config{})}, "fast", 0, true},
+		{[]currly.ConnectorOption{currly.WithTimeout(50 * time.Millisecond)}, "slow", 0, true},
+		{[]currly.ConnectorOption{currly.WithPoolSize(8, 2, 2)}, "fast", 2, false},
+	}
+
+	for i, c := range cases {
+		proto.Store(0)
+		_, _, err := curl(currly.DefaultConnector(c.opts...), currly.PathArg("path", c.path))
+
+		if c.fails != (err != nil) {
+			t.Errorf("Unexpected error in case %v: %v", i, err)
+		}
+
+		if !c.fails && int32(c.proto) != proto.Load() {
+			t.Errorf("Unexpected protocol in case %v (expected: HTTP/%v, actual: HTTP/%v).", i, c.proto, proto.Load())
+		}
+	}
+}
+
+func TestDefaultConnectorUsesProxy(t *testing.T) {
+	var proxied string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	u, _ := url.Parse(proxy.URL)
+	curl, err := currly.Builder().GET().HTTP().Host("api.example.com").PathSegment("users").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err := curl(currly.DefaultConnector(currly.WithProxy(u))); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "http://api.example.com/users" != proxied {
+		t.Errorf("Unexpected proxied URL (expected: %v, actual: %v).", "http://api.example.com/users", proxied)
+	}
+}
+
+func TestConnectorCloseWaitsForInFlightCalls(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.Write([]byte(`"done"`))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().GET().HTTP().Localhost().Port(uint(port)).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	con := currly.Wrap(currly.DefaultConnector(), currly.LoggingMiddleware(slog.New(slog.NewTextHandler(ioutil.Discard, nil))))
+	done := make(chan error, 1)
+
+	go func() {
+		_, _, err := curl(con)
+		done <- err
+	}()
+
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := currly.CloseConnector(ctx, con); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+
+	if _, _, err := curl(con); !errors.Is(err, currly.ErrConnectorClosed) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrConnectorClosed, err)
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Errorf("The in-flight call returned an unexpected error: %v", err)
+	}
+
+	if err := currly.CloseConnector(context.Background(), con); err != nil {
+		t.Errorf("Closing the connector returned an unexpected error: %v", err)
+	}
+}
+
+func TestConnectorCloseReleasesIdleConnectionsWhenTheDeadlineExpires(t *testing.T) {
+	closed := make(chan struct{})
+	idle := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	idle.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateClosed {
+			close(closed)
+		}
+	}
+	idle.Start()
+	defer idle.Close()
+
+	busy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pending"))
+	}))
+	defer busy.Close()
+
+	con := currly.DefaultConnector()
+	r, _ := http.NewRequest(http.MethodGet, idle.URL, nil)
+	resp, err := con.Send(r)
+
+	if err != nil {
+		t.Fatalf("Sending the request returned an unexpected error: %v", err)
+	}
+
+	currly.DrainBody(resp.Body)
+
+	r, _ = http.NewRequest(http.MethodGet, busy.URL, nil)
+	pending, err := con.Send(r)
+
+	if err != nil {
+		t.Fatalf("Sending the request returned an unexpected error: %v", err)
+	}
+
+	defer pending.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := currly.CloseConnector(ctx, con); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Errorf("Closing the connector should close idle connections even when the deadline expires.")
+	}
+}
@@ -0,0 +1,52 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+func ContextArg(ctx context.Context) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		if ctx == nil {
+			return errors.New("currly: context must not be nil")
+		}
+
+		ct.ctx = ctx
+
+		return nil
+	})
+}
+
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+
+	return id, ok && len(id) > 0
+}
+
+func CorrelationIDMiddleware(header string) Middleware {
+	return Named("correlation-id", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			if id, ok := CorrelationIDFromContext(r.Context()); ok && len(r.Header.Get(header)) == 0 {
+				r = r.Clone(r.Context())
+				r.Header.Set(header, id)
+			}
+
+			return next.Send(r)
+		})
+	})
+}
+
+type correlationIDKey struct{}
+
+func baseContext(ct curlTemplate) context.Context {
+	if ct.ctx != nil {
+		return ct.ctx
+	}
+
+	return context.Background()
+}
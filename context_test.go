@@ -0,0 +1,98 @@
+package currly_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+type contextKey struct{}
+
+func TestContextArgReachesConnectorAndMiddleware(t *testing.T) {
+	var req *http.Request
+
+	buf := new(bytes.Buffer)
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	}), currly.LoggingMiddleware(slog.New(slog.NewJSONHandler(buf, nil))), currly.CorrelationIDMiddleware("X-Correlation-ID"))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	ctx := currly.WithCorrelationID(context.WithValue(context.Background(), contextKey{}, "value"), "c-42")
+	_, _, err = curl(con, currly.ContextArg(ctx))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "value" != req.Context().Value(contextKey{}) {
+		t.Errorf("Unexpected context value (expected: %v, actual: %v).", "value", req.Context().Value(contextKey{}))
+	}
+
+	if "c-42" != req.Header.Get("X-Correlation-ID") {
+		t.Errorf("Unexpected correlation ID header (expected: %v, actual: %v).", "c-42", req.Header.Get("X-Correlation-ID"))
+	}
+
+	var rec map[string]interface{}
+
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("The log record should be valid JSON: %v", err)
+	}
+
+	if "c-42" != rec["correlation_id"] {
+		t.Errorf("Unexpected logged correlation ID (expected: %v, actual: %v).", "c-42", rec["correlation_id"])
+	}
+}
+
+func TestContextArgCancelsRequest(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+
+		return nil, r.Context().Err()
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancel()
+
+	_, _, err = curl(con, currly.ContextArg(ctx))
+
+	if context.Canceled != err {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.Canceled, err)
+	}
+}
+
+func TestTemplateTimeoutIsEnforcedWithoutCallerContext(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+
+		return nil, r.Context().Err()
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Timeout(10 * time.Millisecond).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con)
+
+	if context.DeadlineExceeded != err {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+}
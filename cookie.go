@@ -0,0 +1,55 @@
+package currly
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+func CookieArg(c *http.Cookie) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		if c == nil {
+			return errors.New("currly: cookie must not be nil")
+		}
+
+		s := (&http.Cookie{Name: c.Name, Value: c.Value}).String()
+
+		if len(s) == 0 {
+			return errors.New("currly: cookie name is invalid")
+		}
+
+		if v := ct.header.Get("Cookie"); len(v) > 0 {
+			s = strings.Join([]string{v, s}, "; ")
+		}
+
+		ct.header.Set("Cookie", s)
+
+		return nil
+	})
+}
+
+func (r Result) CookieArgs() []Arg {
+	args := make([]Arg, len(r.Cookies))
+
+	for i, c := range r.Cookies {
+		args[i] = CookieArg(c)
+	}
+
+	return args
+}
+
+func (r Result) StoreCookies(jar http.CookieJar) error {
+	if jar == nil {
+		return errors.New("currly: cookie jar must not be nil")
+	}
+
+	if r.URL == nil {
+		return errors.New("currly: result has no request URL")
+	}
+
+	if len(r.Cookies) > 0 {
+		jar.SetCookies(r.URL, r.Cookies)
+	}
+
+	return nil
+}
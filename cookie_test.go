@@ -0,0 +1,75 @@
+package currly_test
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestResultCapturesResponseCookies(t *testing.T) {
+	var cookies string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		cookies = r.Header.Get("Cookie")
+		resp := okResponse(r, "")
+		resp.Header.Add("Set-Cookie", "session=abc; Path=/; HttpOnly")
+		resp.Header.Add("Set-Cookie", "state=xyz")
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Host("example.com").ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	if _, _, err = curl(con, currly.ResultArg(&res)); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if 2 != len(res.Cookies) || "session" != res.Cookies[0].Name || "xyz" != res.Cookies[1].Value {
+		t.Fatalf("Unexpected cookies: %v.", res.Cookies)
+	}
+
+	if _, _, err = curl(con, res.CookieArgs()...); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "session=abc; state=xyz" != cookies {
+		t.Errorf("Unexpected Cookie header (expected: %v, actual: %v).", "session=abc; state=xyz", cookies)
+	}
+
+	jar, _ := cookiejar.New(nil)
+
+	if err = res.StoreCookies(jar); err != nil {
+		t.Fatalf("Storing the cookies returned an unexpected error: %v", err)
+	}
+
+	stored := jar.Cookies(&url.URL{Scheme: "https", Host: "example.com", Path: "/"})
+
+	if 2 != len(stored) {
+		t.Errorf("Unexpected number of stored cookies (expected: %v, actual: %v).", 2, len(stored))
+	}
+}
+
+func TestCookieArgRejectsInvalidCookies(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	for _, c := range []*http.Cookie{nil, {Name: "bad name", Value: "x"}} {
+		if _, _, err = curl(con, currly.CookieArg(c)); err == nil {
+			t.Errorf("Expected an error for cookie %v.", c)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package currly
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func NewFileCookieJar(path string, c Clock) (*FileCookieJar, error) {
+	fj := &FileCookieJar{path: path, clock: clockOrSystem(c), entries: make(map[cookieKey]storedCookie)}
+
+	if err := fj.reset(); err != nil {
+		return nil, err
+	}
+
+	bs, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return fj, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []storedCookie
+
+	if len(strings.TrimSpace(string(bs))) > 0 {
+		if err := json.Unmarshal(bs, &stored); err != nil {
+			return nil, fmt.Errorf("currly: cookie jar '%v' is malformed: %w", path, err)
+		}
+	}
+
+	now := fj.clock.Now()
+
+	for _, sc := range stored {
+		u, err := url.Parse(sc.URL)
+
+		if err != nil || len(u.Host) == 0 || sc.expired(now) {
+			continue
+		}
+
+		if fj.replay(u, sc) {
+			fj.entries[sc.key()] = sc
+		}
+	}
+
+	return fj, nil
+}
+
+func (fj *FileCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	fj.mutex.Lock()
+	defer fj.mutex.Unlock()
+
+	now := fj.clock.Now()
+
+	for _, c := range cookies {
+		sc := storeCookie(u, c, now)
+
+		if sc.expired(now) {
+			fj.jar.SetCookies(u, []*http.Cookie{c})
+			delete(fj.entries, sc.key())
+		} else if fj.replay(u, sc) {
+			fj.entries[sc.key()] = sc
+		}
+	}
+}
+
+func (fj *FileCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	fj.mutex.Lock()
+	defer fj.mutex.Unlock()
+
+	now := fj.clock.Now()
+	pruned := false
+
+	for k, sc := range fj.entries {
+		if sc.expired(now) {
+			delete(fj.entries, k)
+			pruned = true
+		}
+	}
+
+	if pruned {
+		fj.rebuild()
+	}
+
+	return fj.jar.Cookies(u)
+}
+
+func (fj *FileCookieJar) Save() error {
+	fj.mutex.Lock()
+	defer fj.mutex.Unlock()
+
+	now := fj.clock.Now()
+	stored := make([]storedCookie, 0, len(fj.entries))
+
+	for k, sc := range fj.entries {
+		if sc.expired(now) {
+			delete(fj.entries, k)
+		} else {
+			stored = append(stored, sc)
+		}
+	}
+
+	sort.Slice(stored, func(i, j int) bool {
+		a, b := stored[i], stored[j]
+
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		return a.Name < b.Name
+	})
+
+	bs, err := json.MarshalIndent(stored, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return writeFile0600(fj.path, bs)
+}
+
+func (fj *FileCookieJar) reset() error {
+	jar, err := cookiejar.New(nil)
+
+	if err != nil {
+		return err
+	}
+
+	fj.jar = jar
+
+	return nil
+}
+
+func (fj *FileCookieJar) rebuild() {
+	fj.reset()
+
+	for _, sc := range fj.entries {
+		if u, err := url.Parse(sc.URL); err == nil {
+			fj.replay(u, sc)
+		}
+	}
+}
+
+func (fj *FileCookieJar) replay(u *url.URL, sc storedCookie) bool {
+	fj.jar.SetCookies(u, []*http.Cookie{sc.cookie()})
+
+	probe := &url.URL{Scheme: "https", Host: u.Host, Path: sc.Path}
+
+	for _, c := range fj.jar.Cookies(probe) {
+		if c.Name == sc.Name && c.Value == sc.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeFile0600(path string, data []byte) error {
+	f, err := createTemp(filepath.Dir(path), "."+filepath.Base(path)+".")
+
+	if err != nil {
+		return err
+	}
+
+	if err = f.Chmod(0600); err == nil {
+		if _, err = f.Write(data); err == nil {
+			err = f.Sync()
+		}
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+	}
+
+	return err
+}
+
+func storeCookie(u *url.URL, c *http.Cookie, now time.Time) storedCookie {
+	sc := storedCookie{
+		URL:      (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(),
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   strings.ToLower(strings.TrimPrefix(c.Domain, ".")),
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+
+	if len(sc.Domain) == 0 {
+		sc.Domain = strings.ToLower(u.Hostname())
+		sc.HostOnly = true
+	}
+
+	if len(sc.Path) == 0 || sc.Path[0] != '/' {
+		sc.Path = defaultCookiePath(u.Path)
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		sc.Expires = now.Add(-time.Second)
+	case c.MaxAge > 0:
+		sc.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	case !c.Expires.IsZero():
+		sc.Expires = c.Expires
+	}
+
+	return sc
+}
+
+func defaultCookiePath(p string) string {
+	i := strings.LastIndex(p, "/")
+
+	if i <= 0 {
+		return "/"
+	}
+
+	return p[:i]
+}
+
+type FileCookieJar struct {
+	path    string
+	clock   Clock
+	mutex   sync.Mutex
+	jar     *cookiejar.Jar
+	entries map[cookieKey]storedCookie
+}
+
+type storedCookie struct {
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	HostOnly bool      `json:"host_only,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+	Expires  time.Time `json:"expires,omitempty"`
+}
+
+type cookieKey struct {
+	domain string
+	path   string
+	name   string
+}
+
+func (sc storedCookie) key() cookieKey {
+	return cookieKey{domain: sc.Domain, path: sc.Path, name: sc.Name}
+}
+
+func (sc storedCookie) expired(now time.Time) bool {
+	return !sc.Expires.IsZero() && !sc.Expires.After(now)
+}
+
+func (sc storedCookie) cookie() *http.Cookie {
+	c := &http.Cookie{
+		Name:     sc.Name,
+		Value:    sc.Value,
+		Path:     sc.Path,
+		Secure:   sc.Secure,
+		HttpOnly: sc.HttpOnly,
+		Expires:  sc.Expires,
+	}
+
+	if !sc.HostOnly {
+		c.Domain = sc.Domain
+	}
+
+	return c
+}
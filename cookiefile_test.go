@@ -0,0 +1,130 @@
+package currly_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestFileCookieJarPersistsAndPrunesCookies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	clock := currlytest.NewFakeClock(time.Now())
+	u, _ := url.Parse("https://example.com/api/login")
+	jar, err := currly.NewFileCookieJar(path, clock)
+
+	if err != nil {
+		t.Fatalf("Creating the cookie jar returned an unexpected error: %v", err)
+	}
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "abc", Path: "/", HttpOnly: true},
+		{Name: "short", Value: "lived", MaxAge: 60},
+		{Name: "gone", Value: "soon"},
+	})
+	jar.SetCookies(u, []*http.Cookie{{Name: "gone", MaxAge: -1}})
+
+	if err := jar.Save(); err != nil {
+		t.Fatalf("Saving the cookie jar returned an unexpected error: %v", err)
+	}
+
+	fi, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatalf("Reading the cookie jar file info returned an unexpected error: %v", err)
+	}
+
+	if 0600 != fi.Mode().Perm() {
+		t.Errorf("Unexpected cookie jar file mode (expected: %v, actual: %v).", os.FileMode(0600), fi.Mode().Perm())
+	}
+
+	clock.Advance(2 * time.Minute)
+
+	jar, err = currly.NewFileCookieJar(path, clock)
+
+	if err != nil {
+		t.Fatalf("Loading the cookie jar returned an unexpected error: %v", err)
+	}
+
+	cookies := jar.Cookies(u)
+
+	if 1 != len(cookies) || "session" != cookies[0].Name || "abc" != cookies[0].Value {
+		t.Fatalf("Unexpected cookies: %v.", cookies)
+	}
+
+	if err := jar.Save(); err != nil {
+		t.Fatalf("Saving the cookie jar returned an unexpected error: %v", err)
+	}
+
+	bs, _ := ioutil.ReadFile(path)
+
+	var stored []map[string]interface{}
+
+	if err := json.Unmarshal(bs, &stored); err != nil || 1 != len(stored) {
+		t.Errorf("Unexpected stored cookies (error: %v): %s", err, bs)
+	}
+}
+
+func TestFileCookieJarRejectsMalformedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := currly.NewFileCookieJar(path, nil); err == nil {
+		t.Errorf("Loading a malformed cookie jar should fail.")
+	}
+}
+
+func TestFileCookieJarDoesNotPersistCrossDomainCookies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	evil, _ := url.Parse("http://evil.example/")
+	bank, _ := url.Parse("http://bank.example/")
+	jar, err := currly.NewFileCookieJar(path, nil)
+
+	if err != nil {
+		t.Fatalf("Creating the cookie jar returned an unexpected error: %v", err)
+	}
+
+	jar.SetCookies(evil, []*http.Cookie{
+		{Name: "session", Value: "stolen", Domain: "bank.example", Path: "/"},
+		{Name: "own", Value: "ok", Path: "/"},
+	})
+
+	if cookies := jar.Cookies(bank); 0 != len(cookies) {
+		t.Fatalf("Unexpected cookies before saving: %v.", cookies)
+	}
+
+	if err := jar.Save(); err != nil {
+		t.Fatalf("Saving the cookie jar returned an unexpected error: %v", err)
+	}
+
+	if bs, _ := ioutil.ReadFile(path); strings.Contains(string(bs), "stolen") {
+		t.Errorf("The rejected cookie should not be persisted: %s", bs)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`[{"url": "http://evil.example/", "name": "session", "value": "stolen", "domain": "bank.example", "path": "/"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if jar, err = currly.NewFileCookieJar(path, nil); err != nil {
+		t.Fatalf("Loading the cookie jar returned an unexpected error: %v", err)
+	}
+
+	if cookies := jar.Cookies(bank); 0 != len(cookies) {
+		t.Errorf("Unexpected cookies after reloading: %v.", cookies)
+	}
+
+	if cookies := jar.Cookies(evil); 0 != len(cookies) {
+		t.Errorf("Unexpected cookies for the setting host after reloading a tampered file: %v.", cookies)
+	}
+}
@@ -0,0 +1,89 @@
+package currly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func StaticCredentials(c Credential) CredentialProvider {
+	return CredentialProviderFunc(func(ctx context.Context, host string) (Credential, error) {
+		return c, nil
+	})
+}
+
+func EnvCredentials(prefix string) CredentialProvider {
+	return CredentialProviderFunc(func(ctx context.Context, host string) (Credential, error) {
+		c := Credential{
+			Username: os.Getenv(prefix + "USERNAME"),
+			Password: os.Getenv(prefix + "PASSWORD"),
+			Token:    os.Getenv(prefix + "TOKEN"),
+		}
+
+		if c == (Credential{}) {
+			return c, fmt.Errorf("currly: no credentials in environment variables with prefix '%v'", prefix)
+		}
+
+		return c, nil
+	})
+}
+
+func FileCredentials(path string) CredentialProvider {
+	return CredentialProviderFunc(func(ctx context.Context, host string) (Credential, error) {
+		var c Credential
+
+		bs, err := ioutil.ReadFile(path)
+
+		if err != nil {
+			return c, err
+		}
+
+		if err := json.Unmarshal(bs, &c); err != nil {
+			return c, fmt.Errorf("currly: invalid credentials file '%v': %w", path, err)
+		}
+
+		return c, nil
+	})
+}
+
+type CredentialProvider interface {
+	Credentials(ctx context.Context, host string) (Credential, error)
+}
+
+type CredentialProviderFunc func(ctx context.Context, host string) (Credential, error)
+
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+func (f CredentialProviderFunc) Credentials(ctx context.Context, host string) (Credential, error) {
+	return f(ctx, host)
+}
+
+func applyCredentialProvider(ct *curlTemplate, host string) error {
+	if ct.credentials != emptyCredentials || len(ct.header.Get("Authorization")) > 0 {
+		return nil
+	}
+
+	c, err := ct.credentialProvider.Credentials(baseContext(*ct), host)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(c.Token) > 0:
+		ct.header.Set("Authorization", "Bearer "+c.Token)
+	case len(c.Username) > 0:
+		ct.credentials = credentials{c.Username, c.Password}
+	default:
+		return errors.New("currly: credential provider returned empty credentials")
+	}
+
+	return nil
+}
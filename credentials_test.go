@@ -0,0 +1,118 @@
+package currly_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestCredentialProvidersAreResolvedPerCall(t *testing.T) {
+	var authorization string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		authorization = r.Header.Get("Authorization")
+
+		return okResponse(r, ""), nil
+	})
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	t.Setenv("CURRLY_TEST_TOKEN", "env-token")
+
+	cases := []struct {
+		provider currly.CredentialProvider
+		rotate   func()
+		expected []string
+	}{
+		{
+			currly.StaticCredentials(currly.Credential{Username: "user", Password: "secret"}),
+			func() {},
+			[]string{"Basic dXNlcjpzZWNyZXQ=", "Basic dXNlcjpzZWNyZXQ="},
+		},
+		{
+			currly.EnvCredentials("CURRLY_TEST_"),
+			func() { t.Setenv("CURRLY_TEST_TOKEN", "rotated") },
+			[]string{"Bearer env-token", "Bearer rotated"},
+		},
+		{
+			currly.FileCredentials(path),
+			func() { ioutil.WriteFile(path, []byte(`{"token":"rotated"}`), 0600) },
+			[]string{"Bearer file-token", "Bearer rotated"},
+		},
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"token":"file-token"}`), 0600); err != nil {
+		t.Fatalf("Writing the credentials file returned an unexpected error: %v", err)
+	}
+
+	for _, c := range cases {
+		curl, err := currly.Builder().GET().HTTPS().Localhost().CredentialsFrom(c.provider).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		for i, expected := range c.expected {
+			if i > 0 {
+				c.rotate()
+			}
+
+			if _, _, err = curl(con); err != nil {
+				t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+			}
+
+			if expected != authorization {
+				t.Errorf("Unexpected Authorization header (expected: %v, actual: %v).", expected, authorization)
+			}
+		}
+
+		if _, _, err = curl(con, currly.BearerTokenArg("explicit")); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if "Bearer explicit" != authorization {
+			t.Errorf("Unexpected Authorization header (expected: %v, actual: %v).", "Bearer explicit", authorization)
+		}
+	}
+}
+
+func TestCredentialProviderReceivesHostAndErrors(t *testing.T) {
+	var host string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	provider := currly.CredentialProviderFunc(func(ctx context.Context, h string) (currly.Credential, error) {
+		host = h
+
+		return currly.Credential{}, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Host("api.example.com").CredentialsFrom(provider).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err == nil {
+		t.Errorf("Expected an error for empty credentials.")
+	}
+
+	if "api.example.com" != host {
+		t.Errorf("Unexpected host (expected: %v, actual: %v).", "api.example.com", host)
+	}
+
+	for _, p := range []currly.CredentialProvider{currly.EnvCredentials("CURRLY_MISSING_"), currly.FileCredentials(filepath.Join(t.TempDir(), "missing.json"))} {
+		curl, err := currly.Builder().GET().HTTPS().Localhost().CredentialsFrom(p).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		if _, _, err = curl(con); err == nil {
+			t.Errorf("Expected an error for provider %T.", p)
+		}
+	}
+}
@@ -2,26 +2,46 @@ package currly
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
 )
 
+const maxDrainBytes = 64 << 10
+
 func ClientConnector(c *http.Client) Connector {
-	return clientConnector{c}
+	return &clientConnector{Client: c}
+}
+
+func DefaultConnector(opts ...ConnectorOption) Connector {
+	o := ConnectorOptions{TLSConfig: &tls.Config{InsecureSkipVerify: true}}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return ConfiguredConnector(o)
 }
 
-func DefaultConnector() Connector {
-	t := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
-	c := &http.Client{Transport: t}
+func TLSServerNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(tlsServerNameKey{}).(string)
 
-	return ClientConnector(c)
+	return name, ok
 }
 
 func Builder() DefineMethod {
@@ -29,53 +49,103 @@ func Builder() DefineMethod {
 }
 
 func PathArg(name, value string) Arg {
-	return argFunc(func(ct *curlTemplate) error {
-		for _, v := range ct.urlTemplate.path {
-			if v.varName() == name && v.bindTo(value) {
-				return nil
-			}
-		}
+	return pathArg(name, value, false)
+}
 
-		return fmt.Errorf("currly: URL path parameter '%v' does not exist", name)
-	})
+func PathArgExplicit(name, value string) Arg {
+	return pathArg(name, value, true)
 }
 
 func QueryArg(name, value string) Arg {
+	return queryArg(name, value, false)
+}
+
+func QueryArgExplicit(name, value string) Arg {
+	return queryArg(name, value, true)
+}
+
+func QueryFlagArg(name string, enabled bool) Arg {
 	return argFunc(func(ct *curlTemplate) error {
 		for _, v := range ct.urlTemplate.query {
-			if v.varName() == name && v.bindTo(value) {
+			if qf, ok := v.(*queryFlag); ok && qf.name == name {
+				qf.enabled = enabled
+
 				return nil
 			}
 		}
 
-		return fmt.Errorf("currly: URL query parameter '%v' does not exist", name)
+		return fmt.Errorf("currly: URL query flag '%v' does not exist", name)
 	})
 }
 
-func JSONBodyArg(body interface{}) Arg {
-	var bs []byte
-	var err error
+func RawQueryArg(query string) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		if err := validateRawQuery(query); err != nil {
+			return err
+		}
 
-	once := sync.Once{}
+		ct.urlTemplate.query = append(ct.urlTemplate.query, &rawQuery{query})
+
+		return nil
+	})
+}
 
+func BasePathArg(prefix string) Arg {
 	return argFunc(func(ct *curlTemplate) error {
-		once.Do(func() { bs, err = json.Marshal(body) })
+		vs := basePathSegments(prefix)
 
-		if err != nil {
-			return err
+		for _, v := range vs {
+			if err := validateVariable("path", v); err != nil {
+				return err
+			}
 		}
 
-		if ct.header == nil {
-			ct.header = make(http.Header)
+		ct.urlTemplate.basePath = vs
+
+		return nil
+	})
+}
+
+func HostHeaderArg(name string) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		ct.hostHeader = name
+
+		return nil
+	})
+}
+
+func JSONBodyArg(body interface{}) Arg {
+	return jsonBodyArg(body, "application/json; charset=utf-8")
+}
+
+func ExtractorArg(r ResultExtractor) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		if r == nil {
+			return errors.New("currly: result extractor must not be nil")
 		}
 
-		ct.header.Set("Content-Type", "application/json; charset=utf-8")
-		ct.body = ioutil.NopCloser(bytes.NewReader(bs))
+		ct.resultExtractor = r
+		ct.mappers = nil
 
 		return nil
 	})
 }
 
+func DrainBody(body io.ReadCloser) error {
+	if body == nil {
+		return nil
+	}
+
+	_, err := io.Copy(ioutil.Discard, io.LimitReader(body, maxDrainBytes))
+	cerr := body.Close()
+
+	if err != nil {
+		return err
+	}
+
+	return cerr
+}
+
 func JSONStringExtractor() ResultExtractor {
 	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
 		bs, err := ioutil.ReadAll(r.Body)
@@ -131,9 +201,11 @@ type DefineMethod interface {
 	Method(method string) DefineScheme
 	GET() DefineScheme
 	POST() DefineScheme
+	PATCH() DefineScheme
 }
 
 type DefineScheme interface {
+	Scheme(scheme string) DefineHost
 	HTTP() DefineHost
 	HTTPS() DefineHost
 }
@@ -148,6 +220,7 @@ type DefinePort interface {
 	queryPart
 	headerPart
 	credentialsPart
+	optionsPart
 	resultExtractorPart
 	curlFuncPart
 
@@ -159,6 +232,7 @@ type BuildPath interface {
 	queryPart
 	headerPart
 	credentialsPart
+	optionsPart
 	resultExtractorPart
 	curlFuncPart
 }
@@ -167,17 +241,20 @@ type BuildQuery interface {
 	queryPart
 	headerPart
 	credentialsPart
+	optionsPart
 	resultExtractorPart
 	curlFuncPart
 }
 
 type SetCredentials interface {
 	credentialsPart
+	optionsPart
 	resultExtractorPart
 	curlFuncPart
 }
 
 type SetResultExtractor interface {
+	optionsPart
 	resultExtractorPart
 	curlFuncPart
 }
@@ -200,16 +277,22 @@ type ResultExtractorFunc func(r *http.Response) (interface{}, error)
 
 type clientConnector struct {
 	*http.Client
+	serverNameClients sync.Map
+	inFlight          inFlightTracker
 }
 
 type pathPart interface {
 	PathSegment(name string) BuildPath
 	PathParam(name string) BuildPath
+	MatrixParam(name string) BuildPath
 }
 
 type queryPart interface {
 	QuerySegment(name, value string) BuildQuery
 	QueryParam(name string) BuildQuery
+	QueryFlag(name string) BuildQuery
+	RawQuery(query string) BuildQuery
+	SortQuery() BuildQuery
 }
 
 type headerPart interface {
@@ -218,6 +301,124 @@ type headerPart interface {
 
 type credentialsPart interface {
 	Credentials(username, password string) SetResultExtractor
+	OnUnauthorized(refresh AuthRefreshFunc) SetResultExtractor
+	CredentialsFrom(p CredentialProvider) SetResultExtractor
+}
+
+type optionsPart interface {
+	hostHeaderPart
+	tlsServerNamePart
+	statsPart
+	redactionPart
+	hooksPart
+	pipelinePart
+	errorBodyLimitPart
+	timeoutPart
+	phaseTimeoutsPart
+	maxConcurrentPart
+	encodingPart
+	middlewarePart
+	clockPart
+	defaultBodyPart
+	idempotencyPart
+	acceptStatusPart
+	basePathPart
+	namePart
+	versionPart
+	deterministicPart
+	bindEventsPart
+}
+
+type hostHeaderPart interface {
+	HostHeader(name string) SetResultExtractor
+}
+
+type tlsServerNamePart interface {
+	TLSServerName(name string) SetResultExtractor
+}
+
+type statsPart interface {
+	Stats(s *Stats) SetResultExtractor
+}
+
+type redactionPart interface {
+	Redaction(p RedactionPolicy) SetResultExtractor
+}
+
+type pipelinePart interface {
+	Process(p ResponseProcessor) SetResultExtractor
+	Map(m ResultMapper) SetResultExtractor
+}
+
+type timeoutPart interface {
+	Timeout(d time.Duration) SetResultExtractor
+}
+
+type phaseTimeoutsPart interface {
+	ConnectTimeout(d time.Duration) SetResultExtractor
+	ResponseHeaderTimeout(d time.Duration) SetResultExtractor
+	BodyTimeout(d time.Duration) SetResultExtractor
+}
+
+type maxConcurrentPart interface {
+	MaxConcurrent(n int) SetResultExtractor
+	FailFastWhenSaturated() SetResultExtractor
+}
+
+type errorBodyLimitPart interface {
+	ErrorBodyLimit(n int) SetResultExtractor
+}
+
+type encodingPart interface {
+	Encoding(p EncodingPolicy) SetResultExtractor
+}
+
+type middlewarePart interface {
+	Use(mws ...Middleware) SetResultExtractor
+}
+
+type clockPart interface {
+	Clock(c Clock) SetResultExtractor
+}
+
+type defaultBodyPart interface {
+	DefaultBody(body interface{}, s BodyMergeStrategy) SetResultExtractor
+	DefaultBodyFactory(f BodyFactory, s BodyMergeStrategy) SetResultExtractor
+}
+
+type idempotencyPart interface {
+	Idempotent() SetResultExtractor
+	NonIdempotent() SetResultExtractor
+}
+
+type acceptStatusPart interface {
+	AcceptStatus(codes ...int) SetResultExtractor
+}
+
+type basePathPart interface {
+	BasePath(prefix string) SetResultExtractor
+}
+
+type namePart interface {
+	Name(name string) SetResultExtractor
+}
+
+type deterministicPart interface {
+	Deterministic() SetResultExtractor
+}
+
+type versionPart interface {
+	Version(v string) SetResultExtractor
+	Deprecated(replacement string) SetResultExtractor
+}
+
+type bindEventsPart interface {
+	OnBindError(hook func(e BindEvent)) SetResultExtractor
+}
+
+type hooksPart interface {
+	OnBeforeSend(hook func(r *http.Request) error) SetResultExtractor
+	OnAfterReceive(hook func(r *http.Response) error) SetResultExtractor
 }
 
 type resultExtractorPart interface {
@@ -229,28 +430,66 @@ type curlFuncPart interface {
 }
 
 type variable interface {
-	fmt.Stringer
 	varName() string
-	bindTo(value string) bool
+	bindTo(value string, explicit bool) bool
 	copy() variable
+	encode(e *EncodingPolicy) string
+	pattern() string
 }
 
 type curlTemplate struct {
-	method          string
-	urlTemplate     urlTemplate
-	header          http.Header
-	credentials     credentials
-	body            io.ReadCloser
-	resultExtractor ResultExtractor
-	error           error
+	ctx                context.Context
+	method             string
+	urlTemplate        urlTemplate
+	header             http.Header
+	credentials        credentials
+	refreshAuth        AuthRefreshFunc
+	credentialProvider CredentialProvider
+	hostHeader         string
+	tlsServerName      string
+	body               io.ReadCloser
+	contentLength      int64
+	resultExtractor    ResultExtractor
+	timing             *Timing
+	result             *Result
+	stats              *Stats
+	redaction          *RedactionPolicy
+	beforeSend         []func(r *http.Request) error
+	afterReceive       []func(r *http.Response) error
+	processors         []ResponseProcessor
+	mappers            []ResultMapper
+	errorBodyLimit     int
+	timeout            time.Duration
+	connectTimeout     time.Duration
+	headerTimeout      time.Duration
+	bodyTimeout        time.Duration
+	inFlight           chan struct{}
+	saturation         SaturationPolicy
+	middleware         []Middleware
+	chain              Connector
+	clock              Clock
+	defaultBody        BodyFactory
+	bodyMerge          BodyMergeStrategy
+	idempotency        idempotency
+	templateName       string
+	version            string
+	deprecation        *deprecation
+	labels             map[string]string
+	deterministic      bool
+	acceptStatus       []int
+	bindHooks          []func(e BindEvent)
+	error              error
 }
 
 type urlTemplate struct {
-	scheme string
-	host   string
-	port   uint
-	path   []variable
-	query  []variable
+	scheme    string
+	host      string
+	port      uint
+	basePath  []variable
+	path      []variable
+	query     []variable
+	sortQuery bool
+	encoding  *EncodingPolicy
 }
 
 type pathSegment struct {
@@ -258,8 +497,15 @@ type pathSegment struct {
 }
 
 type pathParam struct {
-	name  string
-	value string
+	name     string
+	value    string
+	explicit bool
+}
+
+type matrixParam struct {
+	name     string
+	value    string
+	explicit bool
 }
 
 type querySegment struct {
@@ -268,7 +514,17 @@ type querySegment struct {
 }
 
 type queryParam struct {
-	name  string
+	name     string
+	value    string
+	explicit bool
+}
+
+type queryFlag struct {
+	name    string
+	enabled bool
+}
+
+type rawQuery struct {
 	value string
 }
 
@@ -279,8 +535,61 @@ type credentials struct {
 
 type argFunc func(ct *curlTemplate) error
 
-func (cc clientConnector) Send(r *http.Request) (*http.Response, error) {
-	return cc.Do(r)
+type bindingArg struct {
+	argFunc
+	name  string
+	value string
+}
+
+type tlsServerNameKey struct{}
+
+func (cc *clientConnector) Send(r *http.Request) (*http.Response, error) {
+	if err := cc.inFlight.acquire(); err != nil {
+		return nil, err
+	}
+
+	name, ok := TLSServerNameFromContext(r.Context())
+
+	if !ok {
+		return cc.inFlight.track(cc.Do(r))
+	}
+
+	c, err := cc.serverNameClient(name)
+
+	if err != nil {
+		return cc.inFlight.track(nil, err)
+	}
+
+	return cc.inFlight.track(c.Do(r))
+}
+
+func (cc *clientConnector) serverNameClient(name string) (*http.Client, error) {
+	if c, ok := cc.serverNameClients.Load(name); ok {
+		return c.(*http.Client), nil
+	}
+
+	var t *http.Transport
+
+	switch rt := cc.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return nil, fmt.Errorf("currly: TLS server name '%v' requires an *http.Transport", name)
+	}
+
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+
+	t.TLSClientConfig.ServerName = name
+
+	c := *cc.Client
+	c.Transport = t
+	actual, _ := cc.serverNameClients.LoadOrStore(name, &c)
+
+	return actual.(*http.Client), nil
 }
 
 func (ct curlTemplate) Method(method string) DefineScheme {
@@ -301,29 +610,47 @@ func (ct curlTemplate) POST() DefineScheme {
 	return ct.Method(http.MethodPost)
 }
 
-func (ct curlTemplate) HTTP() DefineHost {
+func (ct curlTemplate) PATCH() DefineScheme {
+	return ct.Method(http.MethodPatch)
+}
+
+func (ct curlTemplate) Scheme(scheme string) DefineHost {
 	if ct.error != nil {
 		return ct
 	}
 
-	ct.urlTemplate.scheme = "http"
+	ct.urlTemplate.scheme = scheme
 
 	return ct
 }
 
+func (ct curlTemplate) HTTP() DefineHost {
+	return ct.Scheme("http")
+}
+
 func (ct curlTemplate) HTTPS() DefineHost {
+	return ct.Scheme("https")
+}
+
+func (ct curlTemplate) Host(host string) DefinePort {
 	if ct.error != nil {
 		return ct
 	}
 
-	ct.urlTemplate.scheme = "https"
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
 
-	return ct
-}
+	if !isASCII(host) {
+		ascii, err := idna.Lookup.ToASCII(host)
 
-func (ct curlTemplate) Host(host string) DefinePort {
-	if ct.error != nil {
-		return ct
+		if err != nil {
+			ct.error = &BuildError{"host", host, err.Error()}
+
+			return ct
+		}
+
+		host = ascii
 	}
 
 	ct.urlTemplate.host = host
@@ -365,6 +692,16 @@ func (ct curlTemplate) PathParam(name string) BuildPath {
 	return ct
 }
 
+func (ct curlTemplate) MatrixParam(name string) BuildPath {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.urlTemplate.path = append(ct.urlTemplate.path, &matrixParam{name: name})
+
+	return ct
+}
+
 func (ct curlTemplate) QuerySegment(name, value string) BuildQuery {
 	if ct.error != nil {
 		return ct
@@ -385,6 +722,36 @@ func (ct curlTemplate) QueryParam(name string) BuildQuery {
 	return ct
 }
 
+func (ct curlTemplate) QueryFlag(name string) BuildQuery {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.urlTemplate.query = append(ct.urlTemplate.query, &queryFlag{name, true})
+
+	return ct
+}
+
+func (ct curlTemplate) RawQuery(query string) BuildQuery {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.urlTemplate.query = append(ct.urlTemplate.query, &rawQuery{query})
+
+	return ct
+}
+
+func (ct curlTemplate) SortQuery() BuildQuery {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.urlTemplate.sortQuery = true
+
+	return ct
+}
+
 func (ct curlTemplate) Header(header http.Header) SetCredentials {
 	if ct.error != nil {
 		return ct
@@ -405,82 +772,543 @@ func (ct curlTemplate) Credentials(username, password string) SetResultExtractor
 	return ct
 }
 
-func (ct curlTemplate) ResultExtractor(r ResultExtractor) BuildCurl {
+func (ct curlTemplate) OnUnauthorized(refresh AuthRefreshFunc) SetResultExtractor {
 	if ct.error != nil {
 		return ct
 	}
 
-	ct.resultExtractor = r
+	ct.refreshAuth = refresh
 
 	return ct
 }
 
-func (ct curlTemplate) Build() (CurlFunc, error) {
+func (ct curlTemplate) CredentialsFrom(p CredentialProvider) SetResultExtractor {
 	if ct.error != nil {
-		return nil, ct.error
+		return ct
 	}
 
-	return CurlFunc(func(con Connector, args ...Arg) (int, interface{}, error) {
-		ct = complete(ct, args)
+	if p == nil {
+		ct.error = errors.New("currly: credential provider must not be nil")
 
-		if ct.error != nil {
-			return 0, nil, ct.error
-		}
+		return ct
+	}
 
-		req, err := createRequest(ct)
+	ct.credentialProvider = p
 
-		if err != nil {
-			return 0, nil, err
-		}
+	return ct
+}
 
-		resp, err := con.Send(req)
+func (ct curlTemplate) HostHeader(name string) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
 
-		if err != nil {
-			return 0, nil, err
-		}
+	ct.hostHeader = name
 
-		defer resp.Body.Close()
+	return ct
+}
 
-		ret, err := ct.resultExtractor.Result(resp)
+func (ct curlTemplate) TLSServerName(name string) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
 
-		if err != nil {
-			return resp.StatusCode, nil, err
-		}
+	ct.tlsServerName = name
 
-		return resp.StatusCode, ret, nil
-	}), nil
+	return ct
 }
 
-func complete(ct curlTemplate, args []Arg) curlTemplate {
-	ct = copyCurlTemplate(ct)
+func (ct curlTemplate) Stats(s *Stats) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
 
-	if ct.resultExtractor == nil {
-		ct.resultExtractor = JSONStringExtractor()
+	ct.stats = s
+
+	return ct
+}
+
+func (ct curlTemplate) Redaction(p RedactionPolicy) SetResultExtractor {
+	if ct.error != nil {
+		return ct
 	}
 
-	for _, a := range args {
-		err := a.applyTo(&ct)
+	ct.redaction = &p
 
-		if err != nil {
-			ct.error = err
+	return ct
+}
+
+func (ct curlTemplate) AcceptStatus(codes ...int) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	for _, sc := range codes {
+		if sc < 100 || sc > 999 {
+			ct.error = fmt.Errorf("currly: invalid HTTP status code %v", sc)
 
 			return ct
 		}
 	}
 
+	ct.acceptStatus = append(append([]int(nil), ct.acceptStatus...), codes...)
+
 	return ct
 }
 
-func copyCurlTemplate(ct curlTemplate) curlTemplate {
-	ct.urlTemplate = copyURLTemplate(ct.urlTemplate)
-	ct.header = copyHeader(ct.header)
+func (ct curlTemplate) BasePath(prefix string) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.urlTemplate.basePath = basePathSegments(prefix)
 
 	return ct
 }
 
-func copyHeader(h http.Header) http.Header {
-	hCopy := make(http.Header, len(h))
-
+func (ct curlTemplate) Name(name string) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.templateName = name
+
+	return ct
+}
+
+func (ct curlTemplate) OnBindError(hook func(e BindEvent)) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	hooks := make([]func(e BindEvent), len(ct.bindHooks), len(ct.bindHooks)+1)
+
+	copy(hooks, ct.bindHooks)
+
+	ct.bindHooks = append(hooks, hook)
+
+	return ct
+}
+
+func (ct curlTemplate) OnBeforeSend(hook func(r *http.Request) error) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	hooks := make([]func(r *http.Request) error, len(ct.beforeSend), len(ct.beforeSend)+1)
+
+	copy(hooks, ct.beforeSend)
+
+	ct.beforeSend = append(hooks, hook)
+
+	return ct
+}
+
+func (ct curlTemplate) OnAfterReceive(hook func(r *http.Response) error) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	hooks := make([]func(r *http.Response) error, len(ct.afterReceive), len(ct.afterReceive)+1)
+
+	copy(hooks, ct.afterReceive)
+
+	ct.afterReceive = append(hooks, hook)
+
+	return ct
+}
+
+func (ct curlTemplate) Process(p ResponseProcessor) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ps := make([]ResponseProcessor, len(ct.processors), len(ct.processors)+1)
+
+	copy(ps, ct.processors)
+
+	ct.processors = append(ps, p)
+
+	return ct
+}
+
+func (ct curlTemplate) Map(m ResultMapper) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ms := make([]ResultMapper, len(ct.mappers), len(ct.mappers)+1)
+
+	copy(ms, ct.mappers)
+
+	ct.mappers = append(ms, m)
+
+	return ct
+}
+
+func (ct curlTemplate) ErrorBodyLimit(n int) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.errorBodyLimit = n
+
+	return ct
+}
+
+func (ct curlTemplate) Timeout(d time.Duration) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.timeout = d
+
+	return ct
+}
+
+func (ct curlTemplate) ConnectTimeout(d time.Duration) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.connectTimeout = d
+
+	return ct
+}
+
+func (ct curlTemplate) ResponseHeaderTimeout(d time.Duration) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.headerTimeout = d
+
+	return ct
+}
+
+func (ct curlTemplate) BodyTimeout(d time.Duration) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.bodyTimeout = d
+
+	return ct
+}
+
+func (ct curlTemplate) MaxConcurrent(n int) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	if n <= 0 {
+		ct.error = fmt.Errorf("currly: invalid concurrency limit %v", n)
+
+		return ct
+	}
+
+	ct.inFlight = make(chan struct{}, n)
+
+	return ct
+}
+
+func (ct curlTemplate) FailFastWhenSaturated() SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.saturation = FailWhenSaturated
+
+	return ct
+}
+
+func (ct curlTemplate) Encoding(p EncodingPolicy) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.urlTemplate.encoding = &p
+
+	return ct
+}
+
+func (ct curlTemplate) Use(mws ...Middleware) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.middleware = append(append([]Middleware(nil), ct.middleware...), mws...)
+
+	return ct
+}
+
+func (ct curlTemplate) Clock(c Clock) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.clock = c
+
+	return ct
+}
+
+func (ct curlTemplate) DefaultBody(body interface{}, s BodyMergeStrategy) SetResultExtractor {
+	return ct.DefaultBodyFactory(func() (interface{}, error) { return body, nil }, s)
+}
+
+func (ct curlTemplate) DefaultBodyFactory(f BodyFactory, s BodyMergeStrategy) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	if f == nil {
+		ct.error = errors.New("currly: body factory must not be nil")
+
+		return ct
+	}
+
+	if s < ReplaceDefaultBody || s > DeepMergeBody {
+		ct.error = fmt.Errorf("currly: invalid body merge strategy %v", s)
+
+		return ct
+	}
+
+	ct.defaultBody = f
+	ct.bodyMerge = s
+
+	return ct
+}
+
+func (ct curlTemplate) Idempotent() SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.idempotency = idempotent
+
+	return ct
+}
+
+func (ct curlTemplate) NonIdempotent() SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.idempotency = nonIdempotent
+
+	return ct
+}
+
+func (ct curlTemplate) ResultExtractor(r ResultExtractor) BuildCurl {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.resultExtractor = r
+
+	return ct
+}
+
+func (ct curlTemplate) Build() (CurlFunc, error) {
+	if ct.error != nil {
+		return nil, ct.error
+	}
+
+	if err := validate(ct); err != nil {
+		return nil, err
+	}
+
+	ar := newAuthRefresher(ct.refreshAuth)
+	clock := clockOrSystem(ct.clock)
+	ct.chain = templateChain(ct.middleware)
+
+	return CurlFunc(func(con Connector, args ...Arg) (int, interface{}, error) {
+		start := clock.Now()
+		sc, ret, labels, err := invoke(ct, ar, con, args)
+
+		if ct.stats != nil {
+			ct.stats.record(clock.Now().Sub(start), sc, labels, err)
+		}
+
+		return sc, ret, err
+	}), nil
+}
+
+func call(ct curlTemplate, con Connector) (int, interface{}, error) {
+	sent := false
+
+	defer func() {
+		if !sent && ct.body != nil {
+			ct.body.Close()
+		}
+	}()
+
+	if ct.error != nil {
+		return 0, nil, ct.error
+	}
+
+	if ct.timeout > 0 {
+		ctx, cancel := withTimeout(baseContext(ct), ct.clock, ct.timeout)
+		ct.ctx = ctx
+
+		defer cancel()
+	}
+
+	var phases *phaseTimeouts
+
+	if ct.connectTimeout > 0 || ct.headerTimeout > 0 || ct.bodyTimeout > 0 {
+		phases = newPhaseTimeouts(baseContext(ct), ct.clock)
+		ct.ctx = phases.ctx
+
+		defer phases.close()
+	}
+
+	release, err := acquire(baseContext(ct), ct.inFlight, ct.saturation)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer release()
+
+	req, err := createRequest(ct, append(Chain(ct.chain), Chain(con)...))
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if ct.chain != nil {
+		req = req.WithContext(context.WithValue(req.Context(), callConnectorKey{}, con))
+		con = ct.chain
+	}
+
+	if ct.timing != nil {
+		tr := newTimingRecorder(time.Now())
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), tr.trace()))
+
+		defer func() { *ct.timing = tr.finish() }()
+	}
+
+	if ct.connectTimeout > 0 {
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), phases.connectTrace(ct.connectTimeout)))
+	}
+
+	for _, h := range ct.beforeSend {
+		if err := h(req); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	sent = true
+	stopHeaderTimeout := phases.start(ct.headerTimeout, ErrResponseHeaderTimeout)
+	resp, err := con.Send(req)
+
+	stopHeaderTimeout()
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	stopBodyTimeout := phases.start(ct.bodyTimeout, ErrBodyTimeout)
+
+	defer stopBodyTimeout()
+
+	resp.Body = newContextBody(req.Context(), resp.Body)
+
+	defer DrainBody(resp.Body)
+
+	recordResponse(ct, resp)
+
+	for _, h := range ct.afterReceive {
+		if err := h(resp); err != nil {
+			return resp.StatusCode, nil, err
+		}
+	}
+
+	er := newExcerptReader(resp.Body, ct.errorBodyLimit)
+	resp.Body = er
+	var ret interface{}
+
+	err = recovering(func() error {
+		ret, err = extract(ct, resp, &er)
+
+		return err
+	})
+
+	if err != nil {
+		if !errors.Is(err, ErrUnexpectedStatus) {
+			ret = nil
+		}
+
+		return resp.StatusCode, ret, er.responseError(resp, err, RedactionPolicyFromContext(req.Context()))
+	}
+
+	recordValue(ct, ret)
+
+	return resp.StatusCode, ret, nil
+}
+
+func complete(ct curlTemplate, args []Arg) curlTemplate {
+	ct = copyCurlTemplate(ct)
+
+	if ct.resultExtractor == nil {
+		ct.resultExtractor = JSONStringExtractor()
+	}
+
+	var errs []error
+
+	for _, a := range args {
+		err := recovering(func() error { return a.applyTo(&ct) })
+
+		if err != nil {
+			errs = append(errs, err)
+			ct.emitBindEvent(BindEvent{Template: ct.templateName, Param: paramOf(a, err), Err: err})
+		}
+	}
+
+	if len(errs) == 1 {
+		ct.error = errs[0]
+	} else if len(errs) > 1 {
+		ct.error = errors.Join(errs...)
+	}
+
+	if ct.error != nil {
+		return ct
+	}
+
+	if len(ct.bindHooks) > 0 {
+		for _, v := range ct.urlTemplate.path {
+			if pp, ok := v.(*pathParam); ok && len(pp.value) == 0 && !pp.explicit {
+				err := fmt.Errorf("currly: URL path parameter '%v' is not bound", pp.name)
+
+				ct.emitBindEvent(BindEvent{Template: ct.templateName, Param: pp.name, Missing: true, Err: err})
+			}
+		}
+	}
+
+	if ct.defaultBody != nil {
+		if ct.error = applyDefaultBody(&ct); ct.error != nil {
+			return ct
+		}
+	}
+
+	if ct.credentialProvider != nil {
+		ct.error = applyCredentialProvider(&ct, ct.urlTemplate.host)
+	}
+
+	return ct
+}
+
+func copyCurlTemplate(ct curlTemplate) curlTemplate {
+	ct.urlTemplate = copyURLTemplate(ct.urlTemplate)
+	ct.header = copyHeader(ct.header)
+
+	return ct
+}
+
+func copyHeader(h http.Header) http.Header {
+	hCopy := make(http.Header, len(h))
+
 	for k, v := range h {
 		vCopy := make([]string, len(v))
 
@@ -510,8 +1338,16 @@ func copyVariables(vs []variable) []variable {
 
 var emptyCredentials credentials
 
-func createRequest(ct curlTemplate) (*http.Request, error) {
-	r, err := http.NewRequest(ct.method, urlString(ct.urlTemplate), ct.body)
+func createRequest(ct curlTemplate, chain []string) (*http.Request, error) {
+	ci := callInfo{urlTemplate: urlPattern(ct.urlTemplate), attempt: 1, redaction: ct.redaction, chain: chain, idempotency: ct.idempotency,
+		templateName: ct.templateName, version: ct.version, deprecation: ct.deprecation, labels: ct.labels}
+	ctx := withCallInfo(baseContext(ct), ci)
+
+	if len(ct.tlsServerName) > 0 {
+		ctx = context.WithValue(ctx, tlsServerNameKey{}, ct.tlsServerName)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, ct.method, urlString(ct.urlTemplate), ct.body)
 
 	if err != nil {
 		return nil, err
@@ -527,11 +1363,68 @@ func createRequest(ct curlTemplate) (*http.Request, error) {
 		r.SetBasicAuth(ct.credentials.username, ct.credentials.password)
 	}
 
+	if len(ct.hostHeader) > 0 {
+		r.Host = ct.hostHeader
+	}
+
+	if ct.contentLength > 0 {
+		r.ContentLength = ct.contentLength
+	}
+
+	if rb, ok := ct.body.(replayableBody); ok {
+		r.GetBody = func() (io.ReadCloser, error) { return rb.replay(), nil }
+	}
+
 	return r, nil
 }
 
+func queryOrder(ut urlTemplate) []variable {
+	if !ut.sortQuery {
+		return ut.query
+	}
+
+	vs := make([]variable, len(ut.query))
+
+	copy(vs, ut.query)
+	sort.SliceStable(vs, func(i, j int) bool {
+		if vs[i].varName() != vs[j].varName() {
+			return vs[i].varName() < vs[j].varName()
+		}
+
+		return vs[i].encode(ut.encoding) < vs[j].encode(ut.encoding)
+	})
+
+	return vs
+}
+
+func hostString(host string) string {
+	if !strings.Contains(host, ":") {
+		return host
+	}
+
+	return "[" + strings.Replace(host, "%", "%25", 1) + "]"
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
 func urlString(ut urlTemplate) string {
-	url := ut.scheme + "://" + ut.host
+	return renderURL(ut, func(v variable) string { return v.encode(ut.encoding) })
+}
+
+func urlPattern(ut urlTemplate) string {
+	return renderURL(ut, variable.pattern)
+}
+
+func renderURL(ut urlTemplate, render func(v variable) string) string {
+	url := ut.scheme + "://" + hostString(ut.host)
 
 	if ut.port > 0 {
 		url = url + ":" + strconv.FormatUint(uint64(ut.port), 10)
@@ -539,26 +1432,29 @@ func urlString(ut urlTemplate) string {
 
 	path := ""
 
-	for _, v := range ut.path {
-		s := v.String()
+	segments := 0
 
-		if len(s) > 0 {
-			if len(path) > 0 {
+	for _, v := range append(append([]variable(nil), ut.basePath...), ut.path...) {
+		s := render(v)
+
+		if pp, ok := v.(*pathParam); len(s) > 0 || (ok && pp.isExplicitlyEmpty()) {
+			if _, ok := v.(*matrixParam); !ok && segments > 0 {
 				path = path + "/"
 			}
 
 			path = path + s
+			segments++
 		}
 	}
 
-	if len(path) > 0 {
+	if segments > 0 {
 		url = url + "/" + path
 	}
 
 	query := ""
 
-	for _, v := range ut.query {
-		s := v.String()
+	for _, v := range queryOrder(ut) {
+		s := render(v)
 
 		if len(s) > 0 {
 			if len(query) > 0 {
@@ -580,7 +1476,7 @@ func (ps *pathSegment) varName() string {
 	return ps.name
 }
 
-func (ps *pathSegment) bindTo(value string) bool {
+func (ps *pathSegment) bindTo(value string, explicit bool) bool {
 	return false
 }
 
@@ -588,7 +1484,11 @@ func (ps *pathSegment) copy() variable {
 	return ps
 }
 
-func (ps *pathSegment) String() string {
+func (ps *pathSegment) encode(e *EncodingPolicy) string {
+	return ps.name
+}
+
+func (ps *pathSegment) pattern() string {
 	return ps.name
 }
 
@@ -596,8 +1496,9 @@ func (pp *pathParam) varName() string {
 	return pp.name
 }
 
-func (pp *pathParam) bindTo(value string) bool {
+func (pp *pathParam) bindTo(value string, explicit bool) bool {
 	pp.value = value
+	pp.explicit = explicit
 
 	return true
 }
@@ -608,19 +1509,56 @@ func (pp *pathParam) copy() variable {
 	return &copy
 }
 
-func (pp *pathParam) String() string {
+func (pp *pathParam) isExplicitlyEmpty() bool {
+	return pp.explicit && len(pp.value) == 0
+}
+
+func (pp *pathParam) encode(e *EncodingPolicy) string {
 	if len(pp.value) == 0 {
 		return ""
 	}
 
-	return url.PathEscape(pp.value)
+	return e.path(pp.value)
+}
+
+func (pp *pathParam) pattern() string {
+	return "{" + pp.name + "}"
+}
+
+func (mp *matrixParam) varName() string {
+	return mp.name
+}
+
+func (mp *matrixParam) bindTo(value string, explicit bool) bool {
+	mp.value = value
+	mp.explicit = explicit
+
+	return true
+}
+
+func (mp *matrixParam) copy() variable {
+	copy := *mp
+
+	return &copy
+}
+
+func (mp *matrixParam) encode(e *EncodingPolicy) string {
+	if len(mp.value) == 0 && !mp.explicit {
+		return ""
+	}
+
+	return ";" + e.path(mp.name) + "=" + e.path(mp.value)
+}
+
+func (mp *matrixParam) pattern() string {
+	return ";" + url.PathEscape(mp.name) + "={" + mp.name + "}"
 }
 
 func (qs *querySegment) varName() string {
 	return qs.name
 }
 
-func (qs *querySegment) bindTo(value string) bool {
+func (qs *querySegment) bindTo(value string, explicit bool) bool {
 	return false
 }
 
@@ -628,16 +1566,21 @@ func (qs *querySegment) copy() variable {
 	return qs
 }
 
-func (qs *querySegment) String() string {
-	return url.QueryEscape(qs.name) + "=" + url.QueryEscape(qs.value)
+func (qs *querySegment) encode(e *EncodingPolicy) string {
+	return e.query(qs.name) + "=" + e.query(qs.value)
+}
+
+func (qs *querySegment) pattern() string {
+	return qs.encode(nil)
 }
 
 func (qp *queryParam) varName() string {
 	return qp.name
 }
 
-func (qp *queryParam) bindTo(value string) bool {
+func (qp *queryParam) bindTo(value string, explicit bool) bool {
 	qp.value = value
+	qp.explicit = explicit
 
 	return true
 }
@@ -648,12 +1591,123 @@ func (qp *queryParam) copy() variable {
 	return &copy
 }
 
-func (qp *queryParam) String() string {
-	if len(qp.value) == 0 {
+func (qp *queryParam) encode(e *EncodingPolicy) string {
+	if len(qp.value) == 0 && !qp.explicit {
 		return ""
 	}
 
-	return url.QueryEscape(qp.name) + "=" + url.QueryEscape(qp.value)
+	return e.query(qp.name) + "=" + e.query(qp.value)
+}
+
+func (qp *queryParam) pattern() string {
+	return url.QueryEscape(qp.name) + "={" + qp.name + "}"
+}
+
+func (qf *queryFlag) varName() string {
+	return qf.name
+}
+
+func (qf *queryFlag) bindTo(value string, explicit bool) bool {
+	return false
+}
+
+func (qf *queryFlag) copy() variable {
+	copy := *qf
+
+	return &copy
+}
+
+func (qf *queryFlag) encode(e *EncodingPolicy) string {
+	if !qf.enabled {
+		return ""
+	}
+
+	return e.query(qf.name)
+}
+
+func (qf *queryFlag) pattern() string {
+	return url.QueryEscape(qf.name)
+}
+
+func (rq *rawQuery) varName() string {
+	return ""
+}
+
+func (rq *rawQuery) bindTo(value string, explicit bool) bool {
+	return false
+}
+
+func (rq *rawQuery) copy() variable {
+	return rq
+}
+
+func (rq *rawQuery) encode(e *EncodingPolicy) string {
+	return rq.value
+}
+
+func (rq *rawQuery) pattern() string {
+	return rq.value
+}
+
+func jsonBodyArg(body interface{}, contentType string) Arg {
+	var bs []byte
+	var err error
+
+	once := sync.Once{}
+
+	return argFunc(func(ct *curlTemplate) error {
+		once.Do(func() { bs, err = json.Marshal(body) })
+
+		if err != nil {
+			return err
+		}
+
+		if ct.header == nil {
+			ct.header = make(http.Header)
+		}
+
+		ct.header.Set("Content-Type", contentType)
+		ct.body = newJSONBody(bs)
+		ct.contentLength = int64(len(bs))
+
+		return nil
+	})
+}
+
+func basePathSegments(prefix string) []variable {
+	var vs []variable
+
+	for _, s := range strings.Split(prefix, "/") {
+		if len(s) > 0 {
+			vs = append(vs, &pathSegment{s})
+		}
+	}
+
+	return vs
+}
+
+func pathArg(name, value string, explicit bool) Arg {
+	return bindingArg{name: name, value: value, argFunc: func(ct *curlTemplate) error {
+		for _, v := range ct.urlTemplate.path {
+			if v.varName() == name && v.bindTo(value, explicit) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("currly: URL path parameter '%v' does not exist", name)
+	}}
+}
+
+func queryArg(name, value string, explicit bool) Arg {
+	return bindingArg{name: name, value: value, argFunc: func(ct *curlTemplate) error {
+		for _, v := range ct.urlTemplate.query {
+			if v.varName() == name && v.bindTo(value, explicit) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("currly: URL query parameter '%v' does not exist", name)
+	}}
 }
 
 func (f argFunc) applyTo(ct *curlTemplate) error {
@@ -1,8 +1,13 @@
 package currly_test
 
 import (
+	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -64,8 +69,388 @@ func TestBuildAndCallCurlWithSimpleURL(t *testing.T) {
 	}
 }
 
+func TestHostHeaderOverridesRequestHost(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTP().Host("10.0.0.1").HostHeader("api.example.com").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "api.example.com" != req.Host {
+		t.Errorf("Unexpected Host header (expected: %v, actual: %v).", "api.example.com", req.Host)
+	}
+
+	if "10.0.0.1" != req.URL.Hostname() {
+		t.Errorf("Unexpected host name (expected: %v, actual: %v).", "10.0.0.1", req.URL.Hostname())
+	}
+
+	_, _, err = curl(con, currly.HostHeaderArg("blue.example.com"))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "blue.example.com" != req.Host {
+		t.Errorf("Unexpected Host header (expected: %v, actual: %v).", "blue.example.com", req.Host)
+	}
+}
+
+func TestTLSServerNameOverridesSNI(t *testing.T) {
+	var serverName string
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverName = r.TLS.ServerName
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().GET().HTTPS().Host(u.Hostname()).Port(uint(port)).
+		TLSServerName("api.example.com").
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(currly.DefaultConnector())
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "api.example.com" != serverName {
+		t.Errorf("Unexpected TLS server name (expected: %v, actual: %v).", "api.example.com", serverName)
+	}
+}
+
+func TestLifecycleHooksRunAroundSend(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	var received int
+
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		OnBeforeSend(func(r *http.Request) error {
+			r.Header.Set("X-Audit", "yes")
+
+			return nil
+		}).
+		OnAfterReceive(func(r *http.Response) error {
+			received = r.StatusCode
+
+			return nil
+		}).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "yes" != req.Header.Get("X-Audit") {
+		t.Errorf("Unexpected X-Audit header (expected: %v, actual: %v).", "yes", req.Header.Get("X-Audit"))
+	}
+
+	if http.StatusOK != received {
+		t.Errorf("Unexpected received status (expected: %v, actual: %v).", http.StatusOK, received)
+	}
+}
+
+func TestBodyIsDrainedAndClosedWhenExtractionFails(t *testing.T) {
+	body := &trackingBody{Reader: strings.NewReader("not json, " + strings.Repeat("x", 1<<12))}
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, "")
+		resp.Body = body
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+			return nil, io.ErrUnexpectedEOF
+		})).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err == nil {
+		t.Fatalf("A failing extractor should return an error.")
+	}
+
+	if !body.closed {
+		t.Errorf("The response body should be closed.")
+	}
+
+	if body.Len() > 0 {
+		t.Errorf("The response body should be drained (remaining: %v bytes).", body.Len())
+	}
+}
+
+func TestMatrixParamsAttachToPrecedingSegment(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		PathSegment("tiles").
+		MatrixParam("version").
+		MatrixParam("lang").
+		PathParam("id").
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con, currly.PathArg("version", "2"), currly.PathArg("lang", "en;us"), currly.PathArg("id", "7"))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "/tiles;version=2;lang=en%3Bus/7" != req.URL.EscapedPath() {
+		t.Errorf("Unexpected path (expected: %v, actual: %v).", "/tiles;version=2;lang=en%3Bus/7", req.URL.EscapedPath())
+	}
+
+	_, _, err = curl(con, currly.PathArg("id", "7"))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "/tiles/7" != req.URL.EscapedPath() {
+		t.Errorf("Unexpected path (expected: %v, actual: %v).", "/tiles/7", req.URL.EscapedPath())
+	}
+}
+
+func TestQueryParamsRenderInDeclarationOrderUnlessSorted(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	b := currly.Builder().GET().HTTPS().Localhost().
+		QueryParam("zeta").
+		QuerySegment("alpha", "2").
+		QueryParam("mid").
+		QuerySegment("alpha", "1")
+	args := []currly.Arg{currly.QueryArg("zeta", "z"), currly.QueryArg("mid", "m")}
+	cases := map[string]currly.BuildCurl{
+		"zeta=z&alpha=2&mid=m&alpha=1": b,
+		"alpha=1&alpha=2&mid=m&zeta=z": b.SortQuery(),
+	}
+
+	for expected, tmpl := range cases {
+		curl, err := tmpl.Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		if _, _, err = curl(con, args...); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if expected != req.URL.RawQuery {
+			t.Errorf("Unexpected query (expected: %v, actual: %v).", expected, req.URL.RawQuery)
+		}
+	}
+}
+
+func TestRawQueryIsAppendedVerbatim(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		QuerySegment("q", "a b").
+		RawQuery("filter=name%20eq%20'x'").
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con, currly.RawQueryArg("$select=id,name")); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	expected := "q=a+b&filter=name%20eq%20'x'&$select=id,name"
+
+	if expected != req.URL.RawQuery {
+		t.Errorf("Unexpected query (expected: %v, actual: %v).", expected, req.URL.RawQuery)
+	}
+
+	if _, _, err = curl(con, currly.RawQueryArg("a b")); err == nil {
+		t.Errorf("A raw query containing spaces should be rejected.")
+	}
+}
+
+func TestQueryFlagsRenderWithoutValues(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().QueryFlag("verbose").QueryFlag("pretty").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "verbose&pretty" != req.URL.RawQuery {
+		t.Errorf("Unexpected query (expected: %v, actual: %v).", "verbose&pretty", req.URL.RawQuery)
+	}
+
+	if _, _, err = curl(con, currly.QueryFlagArg("verbose", false)); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "pretty" != req.URL.RawQuery {
+		t.Errorf("Unexpected query (expected: %v, actual: %v).", "pretty", req.URL.RawQuery)
+	}
+
+	if _, _, err = curl(con, currly.QueryFlagArg("debug", true)); err == nil {
+		t.Errorf("Toggling an undeclared query flag should fail.")
+	}
+}
+
+func okResponse(r *http.Request, body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     http.StatusText(http.StatusOK),
+		Header:     make(http.Header),
+		Request:    r,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+type trackingBody struct {
+	*strings.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+
+	return nil
+}
+
 type connectorFunc func(r *http.Request) (*http.Response, error)
 
 func (f connectorFunc) Send(r *http.Request) (*http.Response, error) {
 	return f(r)
 }
+
+func TestExplicitArgsRenderEmptyValues(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		PathSegment("users").
+		PathParam("id").
+		MatrixParam("version").
+		PathSegment("posts").
+		QueryParam("filter").
+		QueryParam("page").
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		args     []currly.Arg
+		expected string
+	}{
+		{[]currly.Arg{currly.PathArg("id", ""), currly.QueryArg("filter", "")}, "https://localhost/users/posts"},
+		{[]currly.Arg{currly.PathArgExplicit("id", ""), currly.QueryArgExplicit("filter", "")}, "https://localhost/users//posts?filter="},
+		{[]currly.Arg{currly.PathArgExplicit("version", ""), currly.QueryArgExplicit("page", "0")}, "https://localhost/users;version=/posts?page=0"},
+		{[]currly.Arg{currly.QueryArgExplicit("filter", ""), currly.QueryArg("filter", "")}, "https://localhost/users/posts"},
+	}
+
+	for _, c := range cases {
+		if _, _, err := curl(con, c.args...); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if c.expected != req.URL.String() {
+			t.Errorf("Unexpected URL (expected: %v, actual: %v).", c.expected, req.URL)
+		}
+	}
+}
+
+func TestCallReportsAllBindingErrors(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathParam("id").QueryParam("q").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con,
+		currly.PathArg("identifier", "42"),
+		currly.QueryArg("q", "currly"),
+		currly.ValidatedArg(currly.QueryArg("q", ""), currly.NonEmpty()),
+		currly.QueryFlagArg("verbose", true))
+
+	if err == nil {
+		t.Fatalf("Expected an error for invalid arguments.")
+	}
+
+	for _, s := range []string{"'identifier'", "must not be empty", "'verbose'"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("Expected the error to mention %v (actual: %v).", s, err)
+		}
+	}
+
+	var be *currly.BindError
+
+	if !errors.As(err, &be) || "q" != be.Param {
+		t.Errorf("Unexpected bind error (expected: %v, actual: %v).", "q", be)
+	}
+}
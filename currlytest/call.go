@@ -0,0 +1,295 @@
+package currlytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func Call(t testing.TB, curl currly.CurlFunc, con currly.Connector, args ...currly.Arg) *CallResult {
+	t.Helper()
+
+	c := &CallResult{t: t}
+	recorder := currly.ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := con.Send(r)
+
+		if err != nil || resp.Body == nil {
+			return resp, err
+		}
+
+		bs, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.body = bs
+		resp.Body = ioutil.NopCloser(bytes.NewReader(bs))
+
+		return resp, nil
+	})
+
+	c.status, c.value, c.err = curl(recorder, append(append([]currly.Arg(nil), args...), currly.ResultArg(&c.result))...)
+
+	return c
+}
+
+func (c *CallResult) ExpectNoError() *CallResult {
+	c.t.Helper()
+
+	if c.err != nil {
+		c.t.Errorf("Calling the cURL function returned an unexpected error: %v", c.err)
+	}
+
+	return c
+}
+
+func (c *CallResult) ExpectError(target error) *CallResult {
+	c.t.Helper()
+
+	if !errors.Is(c.err, target) {
+		c.t.Errorf("Unexpected call error (expected: %v, actual: %v).", target, c.err)
+	}
+
+	return c
+}
+
+func (c *CallResult) ExpectStatus(code int) *CallResult {
+	c.t.Helper()
+
+	if code != c.status {
+		c.t.Errorf("Unexpected status code (expected: %v, actual: %v%v).", code, c.status, c.errorHint())
+	}
+
+	return c
+}
+
+func (c *CallResult) ExpectHeader(name, value string) *CallResult {
+	c.t.Helper()
+
+	for _, v := range c.result.Header.Values(name) {
+		if v == value || strings.HasPrefix(v, value+";") {
+			return c
+		}
+	}
+
+	c.t.Errorf("Unexpected header %v (expected: %v, actual: %v%v).", name, value, c.result.Header.Values(name), c.errorHint())
+
+	return c
+}
+
+func (c *CallResult) ExpectBody(body string) *CallResult {
+	c.t.Helper()
+
+	if body != string(c.body) {
+		c.t.Errorf("Unexpected response body%v:\n%v", c.errorHint(), diff(body, string(c.body)))
+	}
+
+	return c
+}
+
+func (c *CallResult) ExpectJSONPath(path string, expected interface{}) *CallResult {
+	c.t.Helper()
+
+	var doc interface{}
+
+	if err := json.Unmarshal(c.body, &doc); err != nil {
+		c.t.Errorf("The response body should be valid JSON%v: %v", c.errorHint(), err)
+
+		return c
+	}
+
+	actual, err := lookupJSONPath(doc, path)
+
+	if err != nil {
+		c.t.Errorf("Resolving JSON path %v failed: %v", path, err)
+
+		return c
+	}
+
+	normalized, err := normalizeJSON(expected)
+
+	if err != nil {
+		c.t.Errorf("The expected value for JSON path %v cannot be encoded: %v", path, err)
+
+		return c
+	}
+
+	if !reflect.DeepEqual(normalized, actual) {
+		c.t.Errorf("Unexpected value at JSON path %v:\n%v", path, diff(indentJSON(normalized), indentJSON(actual)))
+	}
+
+	return c
+}
+
+func (c *CallResult) Result() currly.Result {
+	return c.result
+}
+
+func (c *CallResult) Value() interface{} {
+	return c.value
+}
+
+func (c *CallResult) Body() []byte {
+	return c.body
+}
+
+func (c *CallResult) Err() error {
+	return c.err
+}
+
+func (c *CallResult) errorHint() string {
+	if c.err == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(", call error: %v", c.err)
+}
+
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSON path '%v' must start with '$'", path)
+	}
+
+	v, rest := doc, path[1:]
+
+	for len(rest) > 0 {
+		var key string
+
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			key, rest = rest[1:end+1], rest[end+1:]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key in '%v'", path)
+			}
+
+			key, rest = rest[2:end], rest[end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in '%v'", path)
+			}
+
+			i, err := strconv.Atoi(rest[1:end])
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid index '%v' in '%v'", rest[1:end], path)
+			}
+
+			a, ok := v.([]interface{})
+
+			if !ok || i < 0 || i >= len(a) {
+				return nil, fmt.Errorf("index %v is out of range at '%v'", i, path[:len(path)-len(rest)])
+			}
+
+			v, rest = a[i], rest[end+1:]
+
+			continue
+		default:
+			return nil, fmt.Errorf("unexpected '%c' in '%v'", rest[0], path)
+		}
+
+		m, ok := v.(map[string]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("'%v' is not an object", path[:len(path)-len(rest)-len(key)-1])
+		}
+
+		if v, ok = m[key]; !ok {
+			return nil, fmt.Errorf("key '%v' is missing", key)
+		}
+	}
+
+	return v, nil
+}
+
+func normalizeJSON(v interface{}) (interface{}, error) {
+	bs, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var n interface{}
+
+	err = json.Unmarshal(bs, &n)
+
+	return n, err
+}
+
+func indentJSON(v interface{}) string {
+	bs, _ := json.MarshalIndent(v, "", "  ")
+
+	return string(bs)
+}
+
+func diff(expected, actual string) string {
+	e, a := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	lcs := make([][]int, len(e)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(a)+1)
+	}
+
+	for i := len(e) - 1; i >= 0; i-- {
+		for j := len(a) - 1; j >= 0; j-- {
+			if e[i] == a[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var b strings.Builder
+
+	i, j := 0, 0
+
+	for i < len(e) || j < len(a) {
+		switch {
+		case i < len(e) && j < len(a) && e[i] == a[j]:
+			b.WriteString("  " + e[i] + "\n")
+			i++
+			j++
+		case j < len(a) && (i == len(e) || lcs[i][j+1] > lcs[i+1][j]):
+			b.WriteString("+ " + a[j] + "\n")
+			j++
+		default:
+			b.WriteString("- " + e[i] + "\n")
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+type CallResult struct {
+	t      testing.TB
+	status int
+	value  interface{}
+	err    error
+	result currly.Result
+	body   []byte
+}
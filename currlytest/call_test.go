@@ -0,0 +1,76 @@
+package currlytest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestCallPassesMatchingExpectations(t *testing.T) {
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathSegment("users").PathParam("id").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	mock := Sequence(Respond().JSON(map[string]interface{}{"id": 42, "tags": []string{"admin", "ops"}, "profile": map[string]string{"first.name": "Bob"}}))
+
+	c := Call(t, curl, mock, currly.PathArg("id", "42")).
+		ExpectNoError().
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJSONPath("$.id", 42).
+		ExpectJSONPath("$.tags[1]", "ops").
+		ExpectJSONPath("$.profile['first.name']", "Bob")
+
+	if "/users/42" != mock.Requests()[0].URL.Path {
+		t.Errorf("Unexpected request path (expected: %v, actual: %v).", "/users/42", mock.Requests()[0].URL.Path)
+	}
+
+	if 0 == len(c.Body()) || nil == c.Value() {
+		t.Errorf("The call should expose the response body and value.")
+	}
+}
+
+func TestCallReportsFailedExpectations(t *testing.T) {
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	rt := &recordingT{TB: t}
+
+	Call(rt, curl, Sequence(Respond().Status(http.StatusNotFound).Header("Content-Type", "text/plain").Body(`{"id":7}`))).
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJSONPath("$.id", 42).
+		ExpectJSONPath("$.missing", 1).
+		ExpectBody("{\"id\":42}")
+
+	expected := []string{"Unexpected status code", "Unexpected header Content-Type", "Unexpected value at JSON path $.id:\n- 42\n+ 7", "Resolving JSON path $.missing failed", "Unexpected response body"}
+
+	if len(expected) != len(rt.errors) {
+		t.Fatalf("Unexpected failures (expected: %v, actual: %q).", len(expected), rt.errors)
+	}
+
+	for i, e := range expected {
+		if !strings.HasPrefix(rt.errors[i], e) {
+			t.Errorf("Unexpected failure %v (expected prefix: %q, actual: %q).", i, e, rt.errors[i])
+		}
+	}
+}
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (rt *recordingT) Helper() {}
+
+func (rt *recordingT) Errorf(format string, args ...interface{}) {
+	rt.errors = append(rt.errors, fmt.Sprintf(format, args...))
+}
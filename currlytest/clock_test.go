@@ -0,0 +1,92 @@
+package currlytest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestFakeClockFiresWaitersWhenAdvanced(t *testing.T) {
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	short := clock.After(time.Second)
+	long := clock.After(time.Minute)
+
+	clock.Advance(time.Second)
+
+	select {
+	case now := <-short:
+		if !now.Equal(time.Unix(1, 0)) {
+			t.Errorf("Unexpected fire time (expected: %v, actual: %v).", time.Unix(1, 0), now)
+		}
+	default:
+		t.Errorf("The short waiter should have fired.")
+	}
+
+	select {
+	case <-long:
+		t.Errorf("The long waiter should not have fired yet.")
+	default:
+	}
+
+	if 1 != clock.Waiters() {
+		t.Errorf("Unexpected number of waiters (expected: %v, actual: %v).", 1, clock.Waiters())
+	}
+}
+
+func TestFakeClockDrivesTemplateTimeoutAndStats(t *testing.T) {
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	stats := new(currly.Stats)
+	var deadline time.Time
+
+	con := currly.ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+		deadline, _ = r.Context().Deadline()
+		pr, pw := io.Pipe()
+
+		go func() {
+			<-r.Context().Done()
+			pw.Close()
+		}()
+
+		clock.Advance(time.Second)
+
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: pr, Request: r}, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		Clock(clock).
+		Timeout(time.Minute).
+		Stats(stats).
+		ResultExtractor(currly.BytesExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, _, err := curl(con)
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+
+	if !deadline.Equal(time.Unix(60, 0)) {
+		t.Errorf("Unexpected request deadline (expected: %v, actual: %v).", time.Unix(60, 0), deadline)
+	}
+
+	if p50 := stats.Snapshot().P50; time.Minute+time.Second != p50 {
+		t.Errorf("Unexpected latency (expected: %v, actual: %v).", time.Minute+time.Second, p50)
+	}
+}
@@ -0,0 +1,211 @@
+package currlytest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrDropped = errors.New("currlytest: connection dropped")
+
+func Respond() *Response {
+	return &Response{status: http.StatusOK, header: make(http.Header)}
+}
+
+func Sequence(rs ...*Response) *Mock {
+	return &Mock{responses: rs}
+}
+
+type Response struct {
+	status   int
+	header   http.Header
+	body     []byte
+	err      error
+	delay    time.Duration
+	drop     bool
+	chunk    int
+	interval time.Duration
+}
+
+type Mock struct {
+	mutex     sync.Mutex
+	responses []*Response
+	requests  []*http.Request
+}
+
+func (r *Response) Status(code int) *Response {
+	r.status = code
+
+	return r
+}
+
+func (r *Response) Header(name, value string) *Response {
+	r.header.Add(name, value)
+
+	return r
+}
+
+func (r *Response) Body(body string) *Response {
+	r.body = []byte(body)
+
+	return r
+}
+
+func (r *Response) JSON(v interface{}) *Response {
+	bs, err := json.Marshal(v)
+
+	if err != nil {
+		r.err = err
+
+		return r
+	}
+
+	r.header.Set("Content-Type", "application/json")
+	r.body = bs
+
+	return r
+}
+
+func (r *Response) Fail(err error) *Response {
+	r.err = err
+
+	return r
+}
+
+func (r *Response) Delay(d time.Duration) *Response {
+	r.delay = d
+
+	return r
+}
+
+func (r *Response) Drop() *Response {
+	r.drop = true
+
+	return r
+}
+
+func (r *Response) SlowBody(chunk int, interval time.Duration) *Response {
+	r.chunk = chunk
+	r.interval = interval
+
+	return r
+}
+
+func (r *Response) Send(req *http.Request) (*http.Response, error) {
+	if !wait(req.Context(), r.delay) {
+		return nil, req.Context().Err()
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if r.drop {
+		return nil, ErrDropped
+	}
+
+	header := make(http.Header, len(r.header))
+
+	for k, v := range r.header {
+		header[k] = append([]string(nil), v...)
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(r.body)))
+
+	return &http.Response{
+		StatusCode:    r.status,
+		Status:        strconv.Itoa(r.status) + " " + http.StatusText(r.status),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		ContentLength: int64(len(r.body)),
+		Body:          ioutil.NopCloser(r.bodyReader()),
+		Request:       req,
+	}, nil
+}
+
+func (m *Mock) Send(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		bs, err := ioutil.ReadAll(req.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(bs))
+	}
+
+	m.mutex.Lock()
+	i := len(m.requests)
+	m.requests = append(m.requests, req)
+	m.mutex.Unlock()
+
+	if len(m.responses) == 0 {
+		return Respond().Status(http.StatusNotImplemented).Send(req)
+	}
+
+	if i >= len(m.responses) {
+		i = len(m.responses) - 1
+	}
+
+	return m.responses[i].Send(req)
+}
+
+func (m *Mock) Requests() []*http.Request {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]*http.Request(nil), m.requests...)
+}
+
+func (r *Response) bodyReader() io.Reader {
+	if r.chunk <= 0 {
+		return bytes.NewReader(r.body)
+	}
+
+	return &slowReader{body: r.body, chunk: r.chunk, interval: r.interval}
+}
+
+type slowReader struct {
+	body     []byte
+	chunk    int
+	interval time.Duration
+}
+
+func (sr *slowReader) Read(p []byte) (int, error) {
+	if len(sr.body) == 0 {
+		return 0, io.EOF
+	}
+
+	time.Sleep(sr.interval)
+
+	n := copy(p[:min(len(p), sr.chunk)], sr.body)
+	sr.body = sr.body[n:]
+
+	return n, nil
+}
+
+func wait(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
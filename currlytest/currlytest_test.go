@@ -0,0 +1,72 @@
+package currlytest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestSequenceReplaysResponsesInOrder(t *testing.T) {
+	mock := currlytest.Sequence(
+		currlytest.Respond().Fail(errors.New("connection reset")),
+		currlytest.Respond().Status(http.StatusServiceUnavailable),
+		currlytest.Respond().Status(http.StatusCreated).JSON(map[string]interface{}{"id": 42}).Header("Location", "/users/42"),
+	)
+	curl, err := currly.Builder().POST().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err := curl(mock); err == nil {
+		t.Errorf("The first call should fail.")
+	}
+
+	if sc, _, _ := curl(mock); http.StatusServiceUnavailable != sc {
+		t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusServiceUnavailable, sc)
+	}
+
+	var res currly.Result
+
+	for i := 0; i < 2; i++ {
+		sc, v, err := curl(mock, currly.ResultArg(&res))
+
+		if err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if http.StatusCreated != sc || `{"id":42}` != v {
+			t.Errorf("Unexpected response (expected: %v %v, actual: %v %v).", http.StatusCreated, `{"id":42}`, sc, v)
+		}
+
+		if "/users/42" != res.Header.Get("Location") {
+			t.Errorf("Unexpected Location header (expected: %v, actual: %v).", "/users/42", res.Header.Get("Location"))
+		}
+	}
+
+	if 4 != len(mock.Requests()) {
+		t.Errorf("Unexpected number of recorded requests (expected: %v, actual: %v).", 4, len(mock.Requests()))
+	}
+}
+
+func TestSequenceHonorsDelaysAndDrops(t *testing.T) {
+	mock := currlytest.Sequence(currlytest.Respond().Drop(), currlytest.Respond().Delay(time.Second))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Timeout(20 * time.Millisecond).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err := curl(mock); !errors.Is(err, currlytest.ErrDropped) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currlytest.ErrDropped, err)
+	}
+
+	if _, _, err := curl(mock); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+}
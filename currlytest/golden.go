@@ -0,0 +1,159 @@
+package currlytest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+var updateGolden = flag.Bool("currlytest.update", false, "rewrite golden request snapshots instead of comparing them")
+
+var VolatileHeaders = []string{"Date", "User-Agent", "Traceparent", "X-Correlation-Id", "X-Request-Id", "Idempotency-Key"}
+
+func AssertGolden(t testing.TB, path string, r *http.Request) {
+	t.Helper()
+
+	actual, err := Snapshot(r)
+
+	if err != nil {
+		t.Fatalf("Serializing the request returned an unexpected error: %v", err)
+	}
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Creating the golden file directory returned an unexpected error: %v", err)
+		}
+
+		stored, err := encodeGolden(path, actual)
+
+		if err != nil {
+			t.Fatalf("Compressing the golden file returned an unexpected error: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, stored, 0644); err != nil {
+			t.Fatalf("Writing the golden file returned an unexpected error: %v", err)
+		}
+
+		return
+	}
+
+	stored, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("Reading the golden file returned an unexpected error (run with -currlytest.update to create it): %v", err)
+	}
+
+	expected, err := decodeGolden(path, stored)
+
+	if err != nil {
+		t.Fatalf("Decompressing the golden file returned an unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("Request does not match golden file %v.\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+	}
+}
+
+func Snapshot(r *http.Request) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	p := currly.RedactionPolicyFromContext(r.Context())
+	header := p.Header(r.Header)
+
+	buf.WriteString(r.Method + " " + p.URL(r.URL) + "\n")
+
+	if len(r.Host) > 0 && r.Host != r.URL.Host {
+		buf.WriteString("Host: " + r.Host + "\n")
+	}
+
+	ks := make([]string, 0, len(header))
+
+	for k := range header {
+		if !isVolatile(k) {
+			ks = append(ks, k)
+		}
+	}
+
+	sort.Strings(ks)
+
+	for _, k := range ks {
+		for _, v := range header[k] {
+			buf.WriteString(k + ": " + v + "\n")
+		}
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return buf.Bytes(), nil
+	}
+
+	bs, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(bs))
+
+	buf.WriteString("\n")
+	buf.Write(p.JSON(bs))
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+func encodeGolden(path string, snapshot []byte) ([]byte, error) {
+	if filepath.Ext(path) != ".gz" {
+		return snapshot, nil
+	}
+
+	buf := new(bytes.Buffer)
+	zw, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := zw.Write(snapshot); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeGolden(path string, stored []byte) ([]byte, error) {
+	if filepath.Ext(path) != ".gz" {
+		return stored, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(stored))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer zr.Close()
+
+	return ioutil.ReadAll(zr)
+}
+
+func isVolatile(name string) bool {
+	for _, v := range VolatileHeaders {
+		if http.CanonicalHeaderKey(v) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+
+	return false
+}
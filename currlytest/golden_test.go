@@ -0,0 +1,78 @@
+package currlytest_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestAssertGoldenComparesRequestSnapshot(t *testing.T) {
+	mock := currlytest.Sequence(currlytest.Respond())
+	curl, err := currly.Builder().POST().HTTPS().Localhost().
+		PathSegment("users").
+		QueryParam("notify").
+		QueryParam("access_token").
+		Header(http.Header{"Accept": {"application/json"}, "User-Agent": {"currly/dev"}}).
+		Credentials("bob", "secret").
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(mock,
+		currly.QueryArg("notify", "true"),
+		currly.QueryArg("access_token", "abc123"),
+		currly.JSONBodyArg(map[string]interface{}{"name": "Bob", "password": "hunter2"}))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	currlytest.AssertGolden(t, "testdata/create_user.golden", mock.Requests()[0])
+}
+
+func TestAssertGoldenCompressesGzipFiles(t *testing.T) {
+	mock := currlytest.Sequence(currlytest.Respond())
+	curl, err := currly.Builder().POST().HTTPS().Localhost().PathSegment("reports").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	body := map[string]string{"report": strings.Repeat("lorem ipsum ", 100)}
+
+	if _, _, err = curl(mock, currly.JSONBodyArg(body)); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.golden.gz")
+
+	flag.Set("currlytest.update", "true")
+	currlytest.AssertGolden(t, path, mock.Requests()[0])
+	flag.Set("currlytest.update", "false")
+	currlytest.AssertGolden(t, path, mock.Requests()[0])
+
+	stored, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("Reading the golden file returned an unexpected error: %v", err)
+	}
+
+	snapshot, err := currlytest.Snapshot(mock.Requests()[0])
+
+	if err != nil {
+		t.Fatalf("Serializing the request returned an unexpected error: %v", err)
+	}
+
+	if !bytes.HasPrefix(stored, []byte{0x1f, 0x8b}) || len(stored) >= len(snapshot) {
+		t.Errorf("Unexpected golden file encoding (stored: %v bytes, snapshot: %v bytes).", len(stored), len(snapshot))
+	}
+}
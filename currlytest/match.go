@@ -0,0 +1,170 @@
+package currlytest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+var placeholder = regexp.MustCompile(`\{[^{}]+\}`)
+
+func AssertMatches(t testing.TB, template currly.BuildCurl, r *http.Request) {
+	t.Helper()
+
+	p, err := currly.PatternOf(template)
+
+	if err != nil {
+		t.Fatalf("Reading the template pattern returned an unexpected error: %v", err)
+	}
+
+	if ms := mismatches(p, r); len(ms) > 0 {
+		t.Errorf("Request %v %v does not match template %v %v:\n  %v", r.Method, r.URL, p.Method, p.URL, strings.Join(ms, "\n  "))
+	}
+}
+
+func mismatches(tm currly.Pattern, r *http.Request) []string {
+	var ms []string
+
+	if len(tm.Method) > 0 && tm.Method != r.Method {
+		ms = append(ms, fmt.Sprintf("method: expected %v, actual %v", tm.Method, r.Method))
+	}
+
+	u, err := url.Parse(tm.URL)
+
+	if err != nil {
+		return append(ms, fmt.Sprintf("template URL: %v", err))
+	}
+
+	if len(u.Scheme) > 0 && u.Scheme != r.URL.Scheme {
+		ms = append(ms, fmt.Sprintf("scheme: expected %v, actual %v", u.Scheme, r.URL.Scheme))
+	}
+
+	if len(u.Host) > 0 && u.Host != r.URL.Host {
+		ms = append(ms, fmt.Sprintf("host: expected %v, actual %v", u.Host, r.URL.Host))
+	}
+
+	ms = append(ms, pathMismatches(u.EscapedPath(), r.URL.EscapedPath())...)
+	ms = append(ms, queryMismatches(u.Query(), r.URL.Query())...)
+
+	for k, v := range tm.Header {
+		for _, hv := range v {
+			if !matchesHeader(r.Header.Values(k), hv) {
+				ms = append(ms, fmt.Sprintf("header %v: expected %v, actual %v", k, hv, r.Header.Values(k)))
+			}
+		}
+	}
+
+	return ms
+}
+
+func pathMismatches(expected, actual string) []string {
+	es := strings.Split(strings.Trim(expected, "/"), "/")
+	as := strings.Split(strings.Trim(actual, "/"), "/")
+
+	if len(es) != len(as) {
+		return []string{fmt.Sprintf("path: expected %v, actual %v", expected, actual)}
+	}
+
+	var ms []string
+
+	for i := range es {
+		if !matches(unescape(es[i]), unescape(as[i])) {
+			ms = append(ms, fmt.Sprintf("path segment %v: expected %v, actual %v", i, unescape(es[i]), unescape(as[i])))
+		}
+	}
+
+	return ms
+}
+
+func queryMismatches(expected, actual url.Values) []string {
+	var ms []string
+
+	for _, k := range sortedKeys(expected) {
+		av, ok := actual[k]
+		ev := expected[k]
+
+		if !ok && !isPlaceholder(ev[0]) {
+			ms = append(ms, fmt.Sprintf("query %v: missing", k))
+
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		if len(ev) != len(av) {
+			ms = append(ms, fmt.Sprintf("query %v: expected %v, actual %v", k, ev, av))
+
+			continue
+		}
+
+		for i := range ev {
+			if !matches(ev[i], av[i]) {
+				ms = append(ms, fmt.Sprintf("query %v: expected %v, actual %v", k, ev[i], av[i]))
+			}
+		}
+	}
+
+	for _, k := range sortedKeys(actual) {
+		if _, ok := expected[k]; !ok {
+			ms = append(ms, fmt.Sprintf("query %v: unexpected (value: %v)", k, actual[k]))
+		}
+	}
+
+	return ms
+}
+
+func matchesHeader(actual []string, expected string) bool {
+	for _, v := range actual {
+		if matches(expected, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matches(expected, actual string) bool {
+	if !placeholder.MatchString(expected) {
+		return expected == actual
+	}
+
+	literals := placeholder.Split(expected, -1)
+
+	for i, l := range literals {
+		literals[i] = regexp.QuoteMeta(l)
+	}
+
+	return regexp.MustCompile("^" + strings.Join(literals, ".+") + "$").MatchString(actual)
+}
+
+func isPlaceholder(s string) bool {
+	return placeholder.FindString(s) == s
+}
+
+func unescape(s string) string {
+	if us, err := url.PathUnescape(s); err == nil {
+		return us
+	}
+
+	return s
+}
+
+func sortedKeys(vs url.Values) []string {
+	ks := make([]string, 0, len(vs))
+
+	for k := range vs {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	return ks
+}
@@ -0,0 +1,57 @@
+package currlytest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestMismatchesAcceptsRequestBuiltFromTemplate(t *testing.T) {
+	template := currly.Builder().GET().HTTPS().Localhost().
+		PathSegment("users").
+		PathParam("id").
+		MatrixParam("version").
+		PathSegment("posts").
+		QueryParam("page").
+		QueryParam("filter").
+		QuerySegment("sort", "asc").
+		Header(http.Header{"Accept": {"application/json"}})
+	mock := Sequence(Respond())
+	curl, err := template.Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(mock, currly.PathArg("id", "42"), currly.PathArg("version", "2"), currly.QueryArg("page", "3"))
+
+	AssertMatches(t, template, mock.Requests()[0])
+}
+
+func TestMismatchesReportsEveryMismatch(t *testing.T) {
+	p, err := currly.PatternOf(currly.Builder().POST().HTTPS().Localhost().
+		PathSegment("users").
+		PathParam("id").
+		QuerySegment("page", "1").
+		Header(http.Header{"X-Tenant": {"acme"}}))
+
+	if err != nil {
+		t.Fatalf("Reading the template pattern returned an unexpected error: %v", err)
+	}
+
+	r, _ := http.NewRequest(http.MethodGet, "https://localhost/users/42/posts?debug=true", nil)
+	ms := mismatches(p, r)
+	expected := []string{"method", "path", "query page: missing", "query debug: unexpected", "header X-Tenant"}
+
+	if len(expected) != len(ms) {
+		t.Fatalf("Unexpected mismatches (expected: %v, actual: %v).", len(expected), ms)
+	}
+
+	for i, e := range expected {
+		if !strings.HasPrefix(ms[i], e) {
+			t.Errorf("Unexpected mismatch %v (expected prefix: %v, actual: %v).", i, e, ms[i])
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package currlytest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func NewScenario(t testing.TB) *Scenario {
+	s := &Scenario{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+type Scenario struct {
+	mutex  sync.Mutex
+	server *httptest.Server
+	routes []*route
+}
+
+type route struct {
+	method    string
+	segments  []string
+	handle    func(r *http.Request, params map[string]string) *Response
+	responses []*Response
+	hits      int
+}
+
+func (s *Scenario) Route(method, pattern string, rs ...*Response) *Scenario {
+	return s.add(&route{method: method, segments: split(pattern), responses: rs})
+}
+
+func (s *Scenario) RouteFunc(method, pattern string, handle func(r *http.Request, params map[string]string) *Response) *Scenario {
+	return s.add(&route{method: method, segments: split(pattern), handle: handle})
+}
+
+func (s *Scenario) Template(method string) currly.BuildPath {
+	u, _ := url.Parse(s.server.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+
+	return currly.Builder().Method(method).HTTP().Host(u.Hostname()).Port(uint(port))
+}
+
+func (s *Scenario) Connector() currly.Connector {
+	return currly.ClientConnector(s.server.Client())
+}
+
+func (s *Scenario) Hits(method, pattern string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, rt := range s.routes {
+		if rt.method == method && strings.Join(rt.segments, "/") == strings.Join(split(pattern), "/") {
+			return rt.hits
+		}
+	}
+
+	return 0
+}
+
+func (s *Scenario) add(rt *route) *Scenario {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.routes = append(s.routes, rt)
+
+	return s
+}
+
+func (s *Scenario) serve(w http.ResponseWriter, r *http.Request) {
+	resp := s.respond(r)
+
+	if !wait(r.Context(), resp.delay) {
+		return
+	}
+
+	if resp.drop || resp.err != nil {
+		drop(w)
+
+		return
+	}
+
+	for k, v := range resp.header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.body)))
+	w.WriteHeader(resp.status)
+
+	if resp.chunk <= 0 {
+		w.Write(resp.body)
+
+		return
+	}
+
+	for body := resp.body; len(body) > 0; {
+		n := min(resp.chunk, len(body))
+
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+
+		w.(http.Flusher).Flush()
+		body = body[n:]
+
+		if len(body) > 0 && !wait(r.Context(), resp.interval) {
+			return
+		}
+	}
+}
+
+func (s *Scenario) respond(r *http.Request) *Response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, rt := range s.routes {
+		params, ok := rt.match(r)
+
+		if !ok {
+			continue
+		}
+
+		i := rt.hits
+		rt.hits++
+
+		if rt.handle != nil {
+			return rt.handle(r, params)
+		}
+
+		if len(rt.responses) == 0 {
+			return Respond()
+		}
+
+		return rt.responses[min(i, len(rt.responses)-1)]
+	}
+
+	return Respond().Status(http.StatusNotFound)
+}
+
+func (rt *route) match(r *http.Request) (map[string]string, bool) {
+	if rt.method != r.Method {
+		return nil, false
+	}
+
+	segments := split(r.URL.EscapedPath())
+
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+
+	for i, s := range rt.segments {
+		v, err := url.PathUnescape(segments[i])
+
+		if err != nil {
+			return nil, false
+		}
+
+		if isPlaceholder(s) {
+			params[s[1:len(s)-1]] = v
+		} else if s != v {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+
+	if len(path) == 0 {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+func drop(w http.ResponseWriter) {
+	hj, ok := w.(http.Hijacker)
+
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+
+	conn, _, err := hj.Hijack()
+
+	if err == nil {
+		conn.Close()
+	}
+}
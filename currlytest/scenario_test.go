@@ -0,0 +1,87 @@
+package currlytest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestScenarioServesRoutesWithPathParams(t *testing.T) {
+	s := currlytest.NewScenario(t).
+		RouteFunc(http.MethodGet, "/users/{id}", func(r *http.Request, params map[string]string) *currlytest.Response {
+			return currlytest.Respond().JSON(map[string]string{"id": params["id"]})
+		})
+	curl, err := s.Template(http.MethodGet).PathSegment("users").PathParam("id").
+		ResultExtractor(currly.PlainStringExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, res, err := curl(s.Connector(), currly.PathArg("id", "a b"))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if `{"id":"a b"}` != res {
+		t.Errorf("Unexpected result (expected: %v, actual: %v).", `{"id":"a b"}`, res)
+	}
+
+	if sc, _, _ := curl(s.Connector()); http.StatusNotFound != sc {
+		t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusNotFound, sc)
+	}
+}
+
+func TestScenarioInjectsFaults(t *testing.T) {
+	s := currlytest.NewScenario(t).
+		Route(http.MethodGet, "/flaky", currlytest.Respond().Drop(), currlytest.Respond().Status(http.StatusServiceUnavailable), currlytest.Respond()).
+		Route(http.MethodGet, "/slow", currlytest.Respond().Delay(time.Second)).
+		Route(http.MethodGet, "/stream", currlytest.Respond().Body(strings.Repeat(`{"n":1}`+"\n", 20)).SlowBody(8, 20*time.Millisecond))
+	con := currly.Wrap(s.Connector(), currly.RetryMiddleware(currly.RetryPolicy{MaxAttempts: 3}))
+	flaky, _ := s.Template(http.MethodGet).PathSegment("flaky").Build()
+
+	if sc, _, err := flaky(con); err != nil || http.StatusOK != sc {
+		t.Errorf("Unexpected result after retries (status: %v, error: %v).", sc, err)
+	}
+
+	if 3 != s.Hits(http.MethodGet, "/flaky") {
+		t.Errorf("Unexpected number of hits (expected: %v, actual: %v).", 3, s.Hits(http.MethodGet, "/flaky"))
+	}
+
+	slow, _ := s.Template(http.MethodGet).PathSegment("slow").Timeout(50 * time.Millisecond).Build()
+
+	if _, _, err := slow(s.Connector()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	records := 0
+
+	stream, _ := s.Template(http.MethodGet).PathSegment("stream").
+		ResultExtractor(currly.NDJSONExtractor(func(record json.RawMessage) error {
+			if records++; records == 2 {
+				cancel()
+			}
+
+			return nil
+		})).
+		Build()
+
+	if _, _, err := stream(s.Connector(), currly.ContextArg(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.Canceled, err)
+	}
+
+	if records >= 20 {
+		t.Errorf("The stream should stop after the cancellation (records: %v).", records)
+	}
+}
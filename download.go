@@ -0,0 +1,130 @@
+package currly
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+func AtomicFileExtractor(dest string) ResultExtractor {
+	return fileExtractor(dest, nil, nil)
+}
+
+func VerifiedFileExtractor(dest string, newHash func() hash.Hash, sum []byte) ResultExtractor {
+	return fileExtractor(dest, newHash, sum)
+}
+
+func fileExtractor(dest string, newHash func() hash.Hash, sum []byte) ResultExtractor {
+	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		f, err := createTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".")
+
+		if err != nil {
+			return nil, err
+		}
+
+		var h hash.Hash
+
+		if newHash != nil {
+			h = newHash()
+		}
+
+		n, err := writeAtomically(f, r, h, sum)
+
+		if err == nil {
+			err = keepMode(f.Name(), dest)
+		}
+
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+
+			return nil, err
+		}
+
+		if err = os.Rename(f.Name(), dest); err != nil {
+			os.Remove(f.Name())
+
+			return nil, err
+		}
+
+		return n, syncDir(filepath.Dir(dest))
+	})
+}
+
+func createTemp(dir, prefix string) (*os.File, error) {
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 36))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+
+		if os.IsExist(err) && i < 100 {
+			continue
+		}
+
+		return f, err
+	}
+}
+
+func keepMode(temp, dest string) error {
+	fi, err := os.Stat(dest)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Chmod(temp, fi.Mode().Perm())
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+
+	if err != nil {
+		return err
+	}
+
+	err = d.Sync()
+	cerr := d.Close()
+
+	if err != nil {
+		return err
+	}
+
+	return cerr
+}
+
+func writeAtomically(f *os.File, r *http.Response, h hash.Hash, sum []byte) (int64, error) {
+	var w io.Writer = f
+
+	if h != nil {
+		w = io.MultiWriter(f, h)
+	}
+
+	n, err := io.Copy(w, r.Body)
+
+	if err != nil {
+		return n, err
+	}
+
+	if r.ContentLength >= 0 && n != r.ContentLength {
+		return n, fmt.Errorf("currly: received %v bytes, expected Content-Length %v", n, r.ContentLength)
+	}
+
+	if h != nil && !bytes.Equal(h.Sum(nil), sum) {
+		return n, fmt.Errorf("currly: checksum mismatch (expected: %x, actual: %x)", sum, h.Sum(nil))
+	}
+
+	if err = f.Sync(); err != nil {
+		return n, err
+	}
+
+	return n, f.Close()
+}
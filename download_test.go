@@ -0,0 +1,140 @@
+package currly_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestAtomicFileExtractorRenamesOnlyOnSuccess(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "report.csv")
+	body := "id,name\n42,Bob\n"
+	sum := sha256.Sum256([]byte(body))
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, body)
+		resp.ContentLength = int64(len(body))
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.VerifiedFileExtractor(dest, sha256.New, sum[:])).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	bs, err := ioutil.ReadFile(dest)
+
+	if err != nil || body != string(bs) {
+		t.Errorf("Unexpected file content (expected: %v, actual: %v, error: %v).", body, string(bs), err)
+	}
+
+	dest = filepath.Join(dir, "broken.csv")
+	curl, _ = currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.VerifiedFileExtractor(dest, sha256.New, []byte("wrong"))).
+		Build()
+
+	if _, _, err = curl(con); err == nil {
+		t.Errorf("A checksum mismatch should return an error.")
+	}
+
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("A failed download should not create the destination file.")
+	}
+
+	if fs, _ := ioutil.ReadDir(dir); 1 != len(fs) {
+		t.Errorf("A failed download should not leave temporary files behind (files: %v).", len(fs))
+	}
+}
+
+func TestVerifiedFileExtractorSupportsConcurrentCallsAndUmaskMode(t *testing.T) {
+	dir := t.TempDir()
+	body := "id,name\n42,Bob\n"
+	sum := sha256.Sum256([]byte(body))
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, body)
+		resp.ContentLength = int64(len(body))
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathParam("name").
+		ResultExtractor(currly.ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+			dest := filepath.Join(dir, path.Base(r.Request.URL.Path))
+
+			return currly.VerifiedFileExtractor(dest, sha256.New, sum[:]).Result(r)
+		})).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	shared, err := currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.VerifiedFileExtractor(filepath.Join(dir, "shared.csv"), sha256.New, sum[:])).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 16)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, _, err := curl(con, currly.PathArg("name", fmt.Sprintf("report-%v.csv", i)))
+			errs <- err
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			_, _, err := shared(con)
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("A concurrent download returned an unexpected error: %v", err)
+		}
+	}
+
+	reference := filepath.Join(t.TempDir(), "reference")
+
+	if err := ioutil.WriteFile(reference, nil, 0666); err != nil {
+		t.Fatalf("Writing the reference file returned an unexpected error: %v", err)
+	}
+
+	expected, _ := os.Stat(reference)
+	actual, err := os.Stat(filepath.Join(dir, "report-0.csv"))
+
+	if err != nil {
+		t.Fatalf("Reading the downloaded file returned an unexpected error: %v", err)
+	}
+
+	if expected.Mode() != actual.Mode() {
+		t.Errorf("Unexpected file mode (expected: %v, actual: %v).", expected.Mode(), actual.Mode())
+	}
+}
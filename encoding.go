@@ -0,0 +1,62 @@
+package currly
+
+import (
+	"net/url"
+	"strings"
+)
+
+const upperHex = "0123456789ABCDEF"
+
+type EncodingPolicy struct {
+	SpaceAsPercent20 bool
+	PathSafe         string
+	QuerySafe        string
+	Strict           bool
+}
+
+func (e *EncodingPolicy) path(s string) string {
+	if e == nil {
+		return url.PathEscape(s)
+	}
+
+	return escape(s, false, func(c byte) bool {
+		return isUnreserved(c) ||
+			(!e.Strict && strings.IndexByte("$&+=:@", c) >= 0) ||
+			strings.IndexByte(e.PathSafe, c) >= 0
+	})
+}
+
+func (e *EncodingPolicy) query(s string) string {
+	if e == nil {
+		return url.QueryEscape(s)
+	}
+
+	return escape(s, !e.SpaceAsPercent20 && !e.Strict, func(c byte) bool {
+		return isUnreserved(c) || strings.IndexByte(e.QuerySafe, c) >= 0
+	})
+}
+
+func escape(s string, spaceAsPlus bool, keep func(c byte) bool) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == ' ' && spaceAsPlus:
+			b.WriteByte('+')
+		case c != '%' && keep(c):
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&15])
+		}
+	}
+
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return isAlpha(rune(c)) || isDigit(rune(c)) || strings.IndexByte("-._~", c) >= 0
+}
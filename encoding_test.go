@@ -0,0 +1,50 @@
+package currly_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestEncodingPolicyControlsPercentEncoding(t *testing.T) {
+	cases := []struct {
+		policy   *currly.EncodingPolicy
+		expected string
+	}{
+		{nil, "/files/a:b%2Cc%20d@e?q=x+y%2Cz"},
+		{&currly.EncodingPolicy{SpaceAsPercent20: true, PathSafe: ",", QuerySafe: ","}, "/files/a:b,c%20d@e?q=x%20y,z"},
+		{&currly.EncodingPolicy{Strict: true}, "/files/a%3Ab%2Cc%20d%40e?q=x%20y%2Cz"},
+	}
+
+	for _, c := range cases {
+		var req *http.Request
+
+		con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+			req = r
+
+			return okResponse(r, ""), nil
+		})
+		b := currly.Builder().GET().HTTPS().Localhost().PathSegment("files").PathParam("name").QueryParam("q")
+
+		var tmpl currly.BuildCurl = b
+
+		if c.policy != nil {
+			tmpl = b.Encoding(*c.policy)
+		}
+
+		curl, err := tmpl.Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		if _, _, err = curl(con, currly.PathArg("name", "a:b,c d@e"), currly.QueryArg("q", "x y,z")); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if actual := req.URL.EscapedPath() + "?" + req.URL.RawQuery; c.expected != actual {
+			t.Errorf("Unexpected encoding (expected: %v, actual: %v).", c.expected, actual)
+		}
+	}
+}
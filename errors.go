@@ -0,0 +1,118 @@
+package currly
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"runtime/debug"
+	"strings"
+)
+
+const defaultErrorBodyLimit = 1024
+
+type ResponseError struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	Err         error
+}
+
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+type excerptReader struct {
+	io.ReadCloser
+	limit   int
+	excerpt []byte
+}
+
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("currly: HTTP %v", e.StatusCode)
+
+	if e.Err != nil {
+		msg = msg + ": " + e.Err.Error()
+	}
+
+	if len(e.Body) > 0 {
+		msg = msg + fmt.Sprintf(" (%v body: %q)", e.ContentType, e.Body)
+	}
+
+	return msg
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("currly: recovered from panic: %v\n%s", e.Value, e.Stack)
+}
+
+func recovering(f func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return f()
+}
+
+func newExcerptReader(body io.ReadCloser, limit int) *excerptReader {
+	if limit == 0 {
+		limit = defaultErrorBodyLimit
+	}
+
+	if limit < 0 {
+		limit = 0
+	}
+
+	return &excerptReader{ReadCloser: body, limit: limit}
+}
+
+func (er *excerptReader) Read(p []byte) (int, error) {
+	n, err := er.ReadCloser.Read(p)
+
+	if rest := er.limit - len(er.excerpt); rest > 0 && n > 0 {
+		if n < rest {
+			rest = n
+		}
+
+		er.excerpt = append(er.excerpt, p[:rest]...)
+	}
+
+	return n, err
+}
+
+func (er *excerptReader) responseError(r *http.Response, err error, p RedactionPolicy) *ResponseError {
+	if rest := er.limit - len(er.excerpt); rest > 0 {
+		io.ReadFull(er, make([]byte, rest))
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	body := er.excerpt
+
+	if isJSON(contentType) {
+		body = p.JSON(body)
+	}
+
+	return &ResponseError{
+		StatusCode:  r.StatusCode,
+		ContentType: contentType,
+		Body:        body,
+		Err:         err,
+	}
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
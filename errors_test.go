@@ -0,0 +1,190 @@
+package currly_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestExtractionErrorCarriesBodyExcerpt(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("Service unavailable. ", 10) + "</body></html>"
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, page)
+		resp.StatusCode = http.StatusServiceUnavailable
+		resp.Header.Set("Content-Type", "text/html")
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ErrorBodyLimit(32).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	sc, _, err := curl(con)
+
+	var rerr *currly.ResponseError
+
+	if !errors.As(err, &rerr) {
+		t.Fatalf("Extracting an HTML page as JSON should return a response error, got: %v", err)
+	}
+
+	if http.StatusServiceUnavailable != sc || sc != rerr.StatusCode {
+		t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusServiceUnavailable, rerr.StatusCode)
+	}
+
+	if "text/html" != rerr.ContentType {
+		t.Errorf("Unexpected content type (expected: %v, actual: %v).", "text/html", rerr.ContentType)
+	}
+
+	if page[:32] != string(rerr.Body) {
+		t.Errorf("Unexpected body excerpt (expected: %v, actual: %v).", page[:32], string(rerr.Body))
+	}
+}
+
+func TestPanicsInExtractorsAndArgsAreRecovered(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+			panic("buggy extractor")
+		})).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con)
+
+	var perr *currly.PanicError
+
+	if !errors.As(err, &perr) {
+		t.Fatalf("A panicking extractor should return a panic error, got: %v", err)
+	}
+
+	if "buggy extractor" != perr.Value {
+		t.Errorf("Unexpected panic value (expected: %v, actual: %v).", "buggy extractor", perr.Value)
+	}
+
+	if len(perr.Stack) == 0 {
+		t.Errorf("The panic error should carry a stack trace.")
+	}
+
+	_, _, err = curl(con, currly.JSONBodyArg(panickingMarshaler{}))
+
+	if !errors.As(err, &perr) {
+		t.Fatalf("A panicking argument should return a panic error, got: %v", err)
+	}
+}
+
+type panickingMarshaler struct{}
+
+func (panickingMarshaler) MarshalJSON() ([]byte, error) {
+	panic("buggy marshaler")
+}
+
+func TestExtractionErrorRedactsDecompressedJSONExcerpt(t *testing.T) {
+	body := `{"error":"invalid_grant","refresh_token":"s3cr3t","detail":"` + strings.Repeat("x", 64) + `"}`
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		buf := new(bytes.Buffer)
+		zw := gzip.NewWriter(buf)
+
+		zw.Write([]byte(body))
+		zw.Close()
+
+		resp := okResponse(r, buf.String())
+		resp.StatusCode = http.StatusBadRequest
+		resp.Header.Set("Content-Type", "application/json")
+		resp.Header.Set("Content-Encoding", "gzip")
+
+		return resp, nil
+	})
+
+	for _, limit := range []int{0, 48} {
+		curl, err := currly.Builder().GET().HTTPS().Localhost().
+			Process(currly.GzipProcessor()).
+			ErrorBodyLimit(limit).
+			ResultExtractor(currly.ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+				ioutil.ReadAll(r.Body)
+
+				return nil, errors.New("rejected")
+			})).
+			Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		_, _, err = curl(con)
+
+		var rerr *currly.ResponseError
+
+		if !errors.As(err, &rerr) {
+			t.Fatalf("A failing extractor should return a response error, got: %v", err)
+		}
+
+		if !strings.Contains(string(rerr.Body), `"error":"invalid_grant"`) {
+			t.Errorf("The excerpt should be taken from the decompressed body (actual: %q).", rerr.Body)
+		}
+
+		if strings.Contains(string(rerr.Body), "s3cr3t") || strings.Contains(err.Error(), "s3cr3t") {
+			t.Errorf("The excerpt should be redacted (actual: %q).", rerr.Body)
+		}
+	}
+}
+
+func TestAcceptStatusReturnsRawBodyForOtherStatuses(t *testing.T) {
+	page := "<html><body>Not found</body></html>"
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/ok" {
+			return okResponse(r, `{"ok":true}`), nil
+		}
+
+		resp := okResponse(r, page)
+		resp.StatusCode = http.StatusNotFound
+		resp.Header.Set("Content-Type", "text/html")
+
+		return resp, nil
+	})
+	base := currly.Builder().GET().HTTPS().Localhost()
+
+	for _, path := range []string{"ok", "missing"} {
+		curl, err := base.PathSegment(path).AcceptStatus(http.StatusOK, http.StatusCreated).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		sc, ret, err := curl(con)
+
+		if http.StatusOK == sc {
+			if err != nil || "{\n  \"ok\": true\n}" != ret {
+				t.Errorf("Unexpected result for an accepted status (result: %v, error: %v).", ret, err)
+			}
+
+			continue
+		}
+
+		var rerr *currly.ResponseError
+
+		if !errors.Is(err, currly.ErrUnexpectedStatus) || !errors.As(err, &rerr) || http.StatusNotFound != rerr.StatusCode {
+			t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrUnexpectedStatus, err)
+		}
+
+		if page != ret {
+			t.Errorf("Unexpected raw body (expected: %v, actual: %v).", page, ret)
+		}
+	}
+
+	if _, err := base.AcceptStatus(42).Build(); err == nil {
+		t.Errorf("Expected an error for an invalid status code.")
+	}
+}
@@ -0,0 +1,139 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync/atomic"
+)
+
+func FailoverConnector(p FailoverPolicy, targets ...FailoverTarget) Connector {
+	return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+		if len(targets) == 0 {
+			return nil, errors.New("currly: failover requires at least one target")
+		}
+
+		var resp *http.Response
+		var err error
+		var trace *connTrace
+
+		for i, t := range targets {
+			if i > 0 {
+				if !replayable(r) || !p.failover(r, resp, err, trace) || r.Context().Err() != nil {
+					break
+				}
+
+				if err == nil {
+					DrainBody(resp.Body)
+				}
+			}
+
+			rCopy, cerr := t.request(r, i > 0)
+
+			if cerr != nil {
+				return nil, cerr
+			}
+
+			trace = &connTrace{}
+			rCopy = rCopy.WithContext(httptrace.WithClientTrace(rCopy.Context(), trace.clientTrace()))
+			resp, err = t.Connector.Send(rCopy)
+
+			if err == nil {
+				resp.Request = rCopy.WithContext(context.WithValue(rCopy.Context(), servedByKey{}, t.Name))
+			}
+		}
+
+		return resp, err
+	})
+}
+
+func ServedBy(resp *http.Response) string {
+	if resp == nil || resp.Request == nil {
+		return ""
+	}
+
+	name, _ := resp.Request.Context().Value(servedByKey{}).(string)
+
+	return name
+}
+
+type FailoverPolicy struct {
+	Statuses []int
+}
+
+type FailoverTarget struct {
+	Name      string
+	Connector Connector
+	Host      string
+	Port      uint
+}
+
+type servedByKey struct{}
+
+type connTrace struct {
+	getConn atomic.Bool
+	gotConn atomic.Bool
+}
+
+func (p FailoverPolicy) failover(r *http.Request, resp *http.Response, err error, trace *connTrace) bool {
+	if err != nil {
+		return trace.unsent() || isDialError(err) || callInfoFrom(r.Context()).idempotent(r)
+	}
+
+	for _, sc := range p.Statuses {
+		if resp.StatusCode == sc {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t FailoverTarget) request(r *http.Request, replay bool) (*http.Request, error) {
+	rCopy := r.Clone(r.Context())
+
+	if len(t.Host) > 0 {
+		if rCopy.Host == rCopy.URL.Host {
+			rCopy.Host = ""
+		}
+
+		rCopy.URL.Host = t.Host
+
+		if t.Port > 0 {
+			rCopy.URL.Host = net.JoinHostPort(t.Host, strconv.FormatUint(uint64(t.Port), 10))
+		}
+	}
+
+	if replay && r.GetBody != nil {
+		body, err := r.GetBody()
+
+		if err != nil {
+			return nil, err
+		}
+
+		rCopy.Body = body
+	}
+
+	return rCopy, nil
+}
+
+func (ct *connTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) { ct.getConn.Store(true) },
+		GotConn: func(httptrace.GotConnInfo) { ct.gotConn.Store(true) },
+	}
+}
+
+func (ct *connTrace) unsent() bool {
+	return ct.getConn.Load() && !ct.gotConn.Load()
+}
+
+func isDialError(err error) bool {
+	var oe *net.OpError
+	var de *net.DNSError
+
+	return (errors.As(err, &oe) && oe.Op == "dial") || errors.As(err, &de)
+}
@@ -0,0 +1,123 @@
+package currly_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestFailoverConnectorFallsBackToSecondaries(t *testing.T) {
+	var hosts []string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		hosts = append(hosts, r.URL.Host)
+
+		switch r.URL.Hostname() {
+		case "primary":
+			return nil, errors.New("connection refused")
+		case "secondary":
+			resp := okResponse(r, "")
+			resp.StatusCode = http.StatusServiceUnavailable
+
+			return resp, nil
+		}
+
+		return okResponse(r, "ok"), nil
+	})
+	failover := currly.FailoverConnector(currly.FailoverPolicy{Statuses: []int{http.StatusServiceUnavailable}},
+		currly.FailoverTarget{Name: "primary", Connector: con},
+		currly.FailoverTarget{Name: "secondary", Connector: con, Host: "secondary"},
+		currly.FailoverTarget{Name: "tertiary", Connector: con, Host: "tertiary", Port: 8443})
+	curl, err := currly.Builder().GET().HTTPS().Host("primary").ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	sc, ret, err := curl(failover, currly.ResultArg(&res))
+
+	if err != nil || http.StatusOK != sc || "ok" != ret {
+		t.Fatalf("Unexpected failover result (status: %v, result: %v, error: %v).", sc, ret, err)
+	}
+
+	if "primary secondary tertiary:8443" != strings.Join(hosts, " ") {
+		t.Errorf("Unexpected targets (expected: %v, actual: %v).", "primary secondary tertiary:8443", hosts)
+	}
+
+	if "tertiary" != res.Metadata.ServedBy || "tertiary:8443" != res.URL.Host {
+		t.Errorf("Unexpected serving target (name: %v, URL: %v).", res.Metadata.ServedBy, res.URL)
+	}
+}
+
+func TestFailoverConnectorKeepsResponsesOutsideThePolicy(t *testing.T) {
+	calls := 0
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		resp := okResponse(r, "")
+		resp.StatusCode = http.StatusNotFound
+
+		return resp, nil
+	})
+	failover := currly.FailoverConnector(currly.FailoverPolicy{Statuses: []int{http.StatusServiceUnavailable}},
+		currly.FailoverTarget{Name: "primary", Connector: con},
+		currly.FailoverTarget{Name: "secondary", Connector: con, Host: "secondary"})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	sc, _, _ := curl(failover, currly.ResultArg(&res))
+
+	if http.StatusNotFound != sc || 1 != calls || "primary" != res.Metadata.ServedBy {
+		t.Errorf("Unexpected failover (status: %v, calls: %v, served by: %v).", sc, calls, res.Metadata.ServedBy)
+	}
+}
+
+func TestFailoverConnectorReplaysNonIdempotentRequestsOnlyBeforeSending(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected string
+	}{
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, "primary secondary"},
+		{&net.DNSError{Err: "no such host", Name: "primary"}, "primary secondary"},
+		{currly.ErrResponseHeaderTimeout, "primary"},
+		{&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, "primary"},
+	}
+
+	for i, c := range cases {
+		var hosts []string
+
+		con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+			hosts = append(hosts, r.URL.Hostname())
+
+			if "primary" == r.URL.Hostname() {
+				return nil, c.err
+			}
+
+			return okResponse(r, "ok"), nil
+		})
+		failover := currly.FailoverConnector(currly.FailoverPolicy{},
+			currly.FailoverTarget{Name: "primary", Connector: con},
+			currly.FailoverTarget{Name: "secondary", Connector: con, Host: "secondary"})
+		curl, err := currly.Builder().POST().HTTPS().Host("primary").ResultExtractor(currly.PlainStringExtractor()).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		curl(failover, currly.JSONBodyArg(map[string]int{"amount": 1}))
+
+		if c.expected != strings.Join(hosts, " ") {
+			t.Errorf("Unexpected targets in case %v (expected: %v, actual: %v).", i, c.expected, hosts)
+		}
+	}
+}
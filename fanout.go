@@ -0,0 +1,80 @@
+package currly
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+)
+
+func TargetArg(target HostPort) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		t := ct.Host(target.Host).(curlTemplate)
+
+		if t.error != nil {
+			return t.error
+		}
+
+		ct.urlTemplate.host = t.urlTemplate.host
+
+		if target.Port > 0 {
+			ct.urlTemplate.port = target.Port
+		}
+
+		return nil
+	})
+}
+
+func FanOut(ctx context.Context, targets []HostPort, curl CurlFunc, con Connector, args ...Arg) []FanOutResult {
+	results := make([]FanOutResult, len(targets))
+	wg := sync.WaitGroup{}
+
+	for i, target := range targets {
+		wg.Add(1)
+
+		go func(i int, target HostPort) {
+			defer wg.Done()
+
+			targetArgs := append(append([]Arg{}, args...), ContextArg(ctx), TargetArg(target))
+			r := FanOutResult{Target: target}
+			r.StatusCode, r.Value, r.Error = curl(con, targetArgs...)
+			results[i] = r
+		}(i, target)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func FanOutErrors(results []FanOutResult) []error {
+	var errs []error
+
+	for _, r := range results {
+		if r.Error != nil {
+			errs = append(errs, r.Error)
+		}
+	}
+
+	return errs
+}
+
+type HostPort struct {
+	Host string
+	Port uint
+}
+
+type FanOutResult struct {
+	Target     HostPort
+	StatusCode int
+	Value      interface{}
+	Error      error
+}
+
+func (hp HostPort) String() string {
+	if hp.Port == 0 {
+		return hp.Host
+	}
+
+	return net.JoinHostPort(hp.Host, strconv.FormatUint(uint64(hp.Port), 10))
+}
@@ -0,0 +1,56 @@
+package currly_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestFanOutCallsEveryTarget(t *testing.T) {
+	var mutex sync.Mutex
+
+	urls := make(map[string]bool)
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		urls[r.URL.String()] = true
+		mutex.Unlock()
+
+		if r.URL.Hostname() == "replica-2" {
+			return nil, errors.New("connection refused")
+		}
+
+		return okResponse(r, r.URL.Host), nil
+	})
+	curl, err := currly.Builder().GET().HTTP().Localhost().Port(8080).PathSegment("status").QueryParam("verbose").
+		ResultExtractor(currly.PlainStringExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	targets := []currly.HostPort{{Host: "replica-1"}, {Host: "replica-2", Port: 9090}, {Host: "replica-3", Port: 9091}}
+	results := currly.FanOut(context.Background(), targets, curl, con, currly.QueryArg("verbose", "true"))
+
+	for _, u := range []string{"http://replica-1:8080/status?verbose=true", "http://replica-2:9090/status?verbose=true", "http://replica-3:9091/status?verbose=true"} {
+		if !urls[u] {
+			t.Errorf("Expected a call to %v (actual calls: %v).", u, urls)
+		}
+	}
+
+	if "replica-1:8080" != results[0].Value || "replica-3:9091" != results[2].Value || targets[1] != results[1].Target {
+		t.Errorf("Unexpected fan-out results: %+v.", results)
+	}
+
+	if errs := currly.FanOutErrors(results); 1 != len(errs) || results[1].Error != errs[0] {
+		t.Errorf("Unexpected fan-out errors: %v.", errs)
+	}
+
+	if "replica-2:9090" != targets[1].String() || "replica-1" != targets[0].String() {
+		t.Errorf("Unexpected target strings: %v, %v.", targets[0], targets[1])
+	}
+}
@@ -0,0 +1,120 @@
+package fasthttpconn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/valyala/fasthttp"
+)
+
+func New(c *fasthttp.Client) currly.Connector {
+	if c == nil {
+		c = &fasthttp.Client{}
+	}
+
+	return &connector{client: c}
+}
+
+type connector struct {
+	client *fasthttp.Client
+}
+
+func (c *connector) Send(r *http.Request) (*http.Response, error) {
+	if err := r.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	if name, ok := currly.TLSServerNameFromContext(r.Context()); ok {
+		return nil, fmt.Errorf("currly: TLS server name '%v' is not supported by the fasthttp connector", name)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := toFastRequest(r, req); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if deadline, ok := r.Context().Deadline(); ok {
+		err = c.client.DoDeadline(req, resp, deadline)
+	} else {
+		err = c.client.Do(req, resp)
+	}
+
+	if errors.Is(err, fasthttp.ErrTimeout) && r.Context().Err() != nil {
+		return nil, r.Context().Err()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fromFastResponse(r, resp), nil
+}
+
+func (c *connector) CloseIdleConnections() {
+	c.client.CloseIdleConnections()
+}
+
+func toFastRequest(r *http.Request, req *fasthttp.Request) error {
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL.String())
+
+	for k, vs := range r.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	if len(r.Host) > 0 && r.Host != r.URL.Host {
+		req.Header.SetHost(r.Host)
+		req.UseHostHeader = true
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	defer r.Body.Close()
+
+	bs, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		return err
+	}
+
+	req.SetBody(bs)
+
+	return nil
+}
+
+func fromFastResponse(r *http.Request, resp *fasthttp.Response) *http.Response {
+	header := make(http.Header)
+
+	for k, v := range resp.Header.All() {
+		header.Add(string(k), string(v))
+	}
+
+	body := append([]byte(nil), resp.Body()...)
+
+	return &http.Response{
+		StatusCode:    resp.StatusCode(),
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode(), http.StatusText(resp.StatusCode())),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
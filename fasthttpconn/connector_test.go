@@ -0,0 +1,64 @@
+package fasthttpconn_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/fasthttpconn"
+)
+
+func TestConnectorConvertsRequestsAndResponses(t *testing.T) {
+	var method, path, host, contentType, body string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		method, path, host, contentType, body = r.Method, r.URL.RequestURI(), r.Host, r.Header.Get("Content-Type"), string(bs)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "42")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":42}`))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().POST().HTTP().Localhost().Port(uint(port)).
+		PathSegment("users").
+		QueryParam("notify").
+		HostHeader("api.example.com").
+		ResultExtractor(currly.PlainStringExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	sc, ret, err := curl(fasthttpconn.New(nil),
+		currly.QueryArg("notify", "true"),
+		currly.JSONBodyArg(map[string]string{"name": "Bob"}),
+		currly.ResultArg(&res))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if http.StatusCreated != sc || `{"id":42}` != ret || "42" != res.Header.Get("X-Request-Id") {
+		t.Errorf("Unexpected response (status: %v, result: %v, header: %v).", sc, ret, res.Header)
+	}
+
+	if http.MethodPost != method || "/users?notify=true" != path || "api.example.com" != host {
+		t.Errorf("Unexpected request line (method: %v, path: %v, host: %v).", method, path, host)
+	}
+
+	if "application/json; charset=utf-8" != contentType || `{"name":"Bob"}` != body {
+		t.Errorf("Unexpected request body (content type: %v, body: %v).", contentType, body)
+	}
+}
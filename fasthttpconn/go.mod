@@ -0,0 +1,18 @@
+module github.com/DrDoofenshmirtz/currly/fasthttpconn
+
+go 1.25.0
+
+require (
+	github.com/DrDoofenshmirtz/currly v0.0.0
+	github.com/valyala/fasthttp v1.74.0
+)
+
+require (
+	github.com/klauspost/compress v1.20.0 // indirect
+	github.com/molecule-man/go-brrr v1.0.1 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	golang.org/x/net v0.58.0 // indirect
+	golang.org/x/text v0.41.0 // indirect
+)
+
+replace github.com/DrDoofenshmirtz/currly => ../
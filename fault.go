@@ -0,0 +1,120 @@
+package currly
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var ErrInjectedReset = fmt.Errorf("currly: injected connection reset: %w", syscall.ECONNRESET)
+
+func FaultMiddleware(p FaultPolicy) Middleware {
+	if p.Rand == nil {
+		p.Rand = rand.Float64
+	}
+
+	if p.ErrorStatus == 0 {
+		p.ErrorStatus = http.StatusServiceUnavailable
+	}
+
+	if err := p.validate(); err != nil {
+		return failingMiddleware("fault", err)
+	}
+
+	clock := clockOrSystem(p.Clock)
+
+	return Named("fault", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			if p.inject(p.DelayRate) && !sleep(r, clock, p.Delay) {
+				return nil, r.Context().Err()
+			}
+
+			if p.inject(p.ResetRate) {
+				return nil, ErrInjectedReset
+			}
+
+			if p.inject(p.ErrorRate) {
+				return faultResponse(r, p.ErrorStatus), nil
+			}
+
+			resp, err := next.Send(r)
+
+			if err != nil || !p.inject(p.TruncateRate) {
+				return resp, err
+			}
+
+			bs, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if err != nil {
+				return nil, err
+			}
+
+			resp.Body = ioutil.NopCloser(&truncatedReader{r: bytes.NewReader(bs[:len(bs)/2])})
+
+			return resp, nil
+		})
+	})
+}
+
+type FaultPolicy struct {
+	DelayRate    float64
+	Delay        time.Duration
+	ResetRate    float64
+	ErrorRate    float64
+	ErrorStatus  int
+	TruncateRate float64
+	Rand         func() float64
+	Clock        Clock
+}
+
+func (p FaultPolicy) validate() error {
+	for _, rate := range []float64{p.DelayRate, p.ResetRate, p.ErrorRate, p.TruncateRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("currly: invalid fault rate %v", rate)
+		}
+	}
+
+	if p.ErrorStatus < 500 || p.ErrorStatus > 599 {
+		return fmt.Errorf("currly: invalid fault status %v", p.ErrorStatus)
+	}
+
+	return nil
+}
+
+func (p FaultPolicy) inject(rate float64) bool {
+	return rate > 0 && p.Rand() < rate
+}
+
+func faultResponse(r *http.Request, status int) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          http.NoBody,
+		ContentLength: 0,
+		Request:       r,
+	}
+}
+
+type truncatedReader struct {
+	r io.Reader
+}
+
+func (tr *truncatedReader) Read(p []byte) (int, error) {
+	n, err := tr.r.Read(p)
+
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
@@ -0,0 +1,99 @@
+package currly_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestFaultMiddlewareInjectsFaults(t *testing.T) {
+	calls := 0
+	base := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+
+		return okResponse(r, "hello, currly"), nil
+	})
+	always := func() float64 { return 0 }
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(currly.Wrap(base, currly.FaultMiddleware(currly.FaultPolicy{ResetRate: 1, Rand: always})))
+
+	if !errors.Is(err, syscall.ECONNRESET) || 0 != calls {
+		t.Errorf("Unexpected reset fault (error: %v, calls: %v).", err, calls)
+	}
+
+	sc, _, err := curl(currly.Wrap(base, currly.FaultMiddleware(currly.FaultPolicy{ErrorRate: 0.5, Rand: always})))
+
+	if http.StatusServiceUnavailable != sc || 0 != calls {
+		t.Errorf("Unexpected status code (expected: %v, actual: %v).", http.StatusServiceUnavailable, sc)
+	}
+
+	_, _, err = curl(currly.Wrap(base, currly.FaultMiddleware(currly.FaultPolicy{TruncateRate: 1, Rand: always})))
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) || 1 != calls {
+		t.Errorf("Unexpected truncation fault (error: %v, calls: %v).", err, calls)
+	}
+
+	_, res, err := curl(currly.Wrap(base, currly.FaultMiddleware(currly.FaultPolicy{ErrorRate: 0.5, Rand: func() float64 { return 0.5 }})))
+
+	if err != nil || "hello, currly" != res {
+		t.Errorf("Unexpected result without faults (result: %v, error: %v).", res, err)
+	}
+}
+
+func TestFaultMiddlewareDelaysWithClock(t *testing.T) {
+	clock := currlytest.NewFakeClock(time.Now())
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	}), currly.FaultMiddleware(currly.FaultPolicy{DelayRate: 1, Delay: time.Hour, Clock: clock}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, _, err := curl(con)
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	if err := <-done; err != nil {
+		t.Errorf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+}
+
+func TestFaultMiddlewareRejectsInvalidPolicies(t *testing.T) {
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	for _, p := range []currly.FaultPolicy{{ErrorRate: 2}, {ResetRate: -1}, {ErrorStatus: http.StatusOK}} {
+		calls := 0
+		con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+
+			return okResponse(r, ""), nil
+		}), currly.FaultMiddleware(p))
+
+		if _, _, err := curl(con); err == nil || 0 != calls {
+			t.Errorf("Expected an error for fault policy %+v (calls: %v, error: %v).", p, calls, err)
+		}
+	}
+}
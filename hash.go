@@ -0,0 +1,97 @@
+package currly
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+func RequestHash(r *http.Request, ignoreHeaders ...string) (string, error) {
+	body, err := requestBody(r)
+
+	if err != nil {
+		return "", err
+	}
+
+	ignored := make(map[string]bool, len(ignoreHeaders))
+
+	for _, k := range ignoreHeaders {
+		ignored[http.CanonicalHeaderKey(k)] = true
+	}
+
+	keys := make([]string, 0, len(r.Header))
+
+	for k := range r.Header {
+		if !ignored[http.CanonicalHeaderKey(k)] {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	u := *r.URL
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.RawQuery = u.Query().Encode()
+	u.Fragment = ""
+
+	h := sha256.New()
+
+	writeField(h, strings.ToUpper(r.Method))
+	writeField(h, u.String())
+	writeField(h, strings.ToLower(r.Host))
+
+	for _, k := range keys {
+		writeField(h, http.CanonicalHeaderKey(k))
+		writeField(h, strings.Join(r.Header[k], "\n"))
+	}
+
+	writeField(h, string(body))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func requestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer body.Close()
+
+		return ioutil.ReadAll(body)
+	}
+
+	bs, err := ioutil.ReadAll(r.Body)
+	cerr := r.Body.Close()
+
+	if err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(bs))
+	r.GetBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(bs)), nil }
+
+	return bs, nil
+}
+
+func writeField(h hash.Hash, s string) {
+	fmt.Fprintf(h, "%d:%s;", len(s), s)
+}
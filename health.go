@@ -0,0 +1,64 @@
+package currly
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+func (f CurlFunc) Healthcheck(ctx context.Context, con Connector, o HealthcheckOptions) Health {
+	var h Health
+
+	if len(o.Method) == 0 {
+		o.Method = http.MethodHead
+	}
+
+	probe := argFunc(func(ct *curlTemplate) error {
+		ct.method = o.Method
+
+		if len(o.Path) > 0 {
+			ct.urlTemplate.basePath = nil
+			ct.urlTemplate.path = basePathSegments(o.Path)
+			ct.urlTemplate.query = nil
+
+			for _, v := range ct.urlTemplate.path {
+				if err := validateVariable("path", v); err != nil {
+					return err
+				}
+			}
+		}
+
+		ct.afterReceive = append(ct.afterReceive[:len(ct.afterReceive):len(ct.afterReceive)], func(r *http.Response) error {
+			if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+				h.TLSExpiry = r.TLS.PeerCertificates[0].NotAfter
+			}
+
+			return nil
+		})
+
+		return nil
+	})
+	discard := ResultExtractorFunc(func(r *http.Response) (interface{}, error) { return nil, nil })
+	start := time.Now()
+
+	h.StatusCode, _, h.Error = f(con, ContextArg(ctx), ExtractorArg(discard), probe)
+	h.Latency = time.Since(start)
+	h.Reachable = h.StatusCode > 0
+	h.Healthy = h.Error == nil && h.StatusCode >= 200 && h.StatusCode < 400
+
+	return h
+}
+
+type HealthcheckOptions struct {
+	Method string
+	Path   string
+}
+
+type Health struct {
+	Reachable  bool
+	Healthy    bool
+	StatusCode int
+	Latency    time.Duration
+	TLSExpiry  time.Time
+	Error      error
+}
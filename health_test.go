@@ -0,0 +1,60 @@
+package currly_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestHealthcheckProbesThroughTheTemplate(t *testing.T) {
+	var method, path string
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().POST().HTTPS().Localhost().Port(uint(port)).PathSegment("users").PathParam("id").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	con := currly.DefaultConnector()
+	h := curl.Healthcheck(context.Background(), con, currly.HealthcheckOptions{})
+
+	if !h.Reachable || !h.Healthy || http.MethodHead != method || "/users" != path {
+		t.Errorf("Unexpected health (health: %+v, method: %v, path: %v).", h, method, path)
+	}
+
+	if !h.TLSExpiry.Equal(srv.Certificate().NotAfter) || h.Latency <= 0 {
+		t.Errorf("Unexpected TLS expiry or latency: %+v.", h)
+	}
+
+	h = curl.Healthcheck(context.Background(), con, currly.HealthcheckOptions{Method: http.MethodGet, Path: "/down"})
+
+	if !h.Reachable || h.Healthy || http.StatusServiceUnavailable != h.StatusCode || http.MethodGet != method || "/down" != path {
+		t.Errorf("Unexpected health (health: %+v, method: %v, path: %v).", h, method, path)
+	}
+
+	failing := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	h = curl.Healthcheck(context.Background(), failing, currly.HealthcheckOptions{})
+
+	if h.Reachable || h.Healthy || h.Error == nil {
+		t.Errorf("Unexpected health for an unreachable dependency: %+v.", h)
+	}
+}
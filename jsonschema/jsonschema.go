@@ -0,0 +1,550 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+var keywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true, "items": true,
+	"enum": true, "const": true, "minimum": true, "maximum": true, "exclusiveMinimum": true,
+	"exclusiveMaximum": true, "minLength": true, "maxLength": true, "pattern": true, "minItems": true,
+	"maxItems": true, "allOf": true, "anyOf": true, "oneOf": true, "not": true,
+}
+
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+var typeNames = map[string]bool{
+	"null": true, "boolean": true, "integer": true, "number": true, "string": true, "array": true, "object": true,
+}
+
+func ValidatedExtractor(schema []byte, inner currly.ResultExtractor) currly.ResultExtractor {
+	s, err := compile(schema)
+
+	return currly.ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		bs, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var v interface{}
+
+		if err := json.Unmarshal(bs, &v); err != nil {
+			return nil, &ValidationError{Violations: []Violation{{Path: "$", Message: err.Error()}}}
+		}
+
+		if vs := s.validate("$", v, nil); len(vs) > 0 {
+			return nil, &ValidationError{Violations: vs}
+		}
+
+		rCopy := *r
+		rCopy.Body = ioutil.NopCloser(bytes.NewReader(bs))
+
+		return inner.Result(&rCopy)
+	})
+}
+
+type ValidationError struct {
+	Violations []Violation
+}
+
+type Violation struct {
+	Path    string
+	Message string
+}
+
+type schema struct {
+	types                []string
+	properties           map[string]*schema
+	required             []string
+	additionalProperties *schema
+	noAdditional         bool
+	items                *schema
+	enum                 []interface{}
+	constant             interface{}
+	hasConstant          bool
+	minimum              *float64
+	maximum              *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minItems             *int
+	maxItems             *int
+	allOf                []*schema
+	anyOf                []*schema
+	oneOf                []*schema
+	not                  *schema
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+
+	for i, v := range e.Violations {
+		msgs[i] = v.Path + ": " + v.Message
+	}
+
+	return "jsonschema: response violates schema: " + strings.Join(msgs, "; ")
+}
+
+func compile(bs []byte) (*schema, error) {
+	var raw interface{}
+
+	if err := json.Unmarshal(bs, &raw); err != nil {
+		return nil, fmt.Errorf("jsonschema: invalid schema: %v", err)
+	}
+
+	return compileValue(raw)
+}
+
+func compileValue(raw interface{}) (*schema, error) {
+	switch raw := raw.(type) {
+	case bool:
+		if raw {
+			return &schema{}, nil
+		}
+
+		return &schema{not: &schema{}}, nil
+	case map[string]interface{}:
+		return compileObject(raw)
+	default:
+		return nil, fmt.Errorf("jsonschema: invalid schema value %v", raw)
+	}
+}
+
+func compileObject(raw map[string]interface{}) (*schema, error) {
+	for k := range raw {
+		if !keywords[k] && !annotations[k] {
+			return nil, fmt.Errorf("jsonschema: unsupported keyword '%v'", k)
+		}
+	}
+
+	s := &schema{}
+	var err error
+
+	if s.types, err = types(raw); err != nil {
+		return nil, err
+	}
+
+	if p, ok := raw["properties"]; ok {
+		ps, ok := p.(map[string]interface{})
+
+		if !ok {
+			return nil, invalidKeyword("properties", p)
+		}
+
+		s.properties = make(map[string]*schema, len(ps))
+
+		for k, v := range ps {
+			if s.properties[k], err = compileValue(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if s.required, err = stringList(raw, "required"); err != nil {
+		return nil, err
+	}
+
+	switch ap := raw["additionalProperties"].(type) {
+	case nil:
+		if _, ok := raw["additionalProperties"]; ok {
+			return nil, invalidKeyword("additionalProperties", ap)
+		}
+	case bool:
+		s.noAdditional = !ap
+	case map[string]interface{}:
+		if s.additionalProperties, err = compileObject(ap); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, invalidKeyword("additionalProperties", ap)
+	}
+
+	if is, ok := raw["items"]; ok {
+		if s.items, err = compileValue(is); err != nil {
+			return nil, err
+		}
+	}
+
+	if e, ok := raw["enum"]; ok {
+		es, ok := e.([]interface{})
+
+		if !ok || len(es) == 0 {
+			return nil, invalidKeyword("enum", e)
+		}
+
+		s.enum = es
+	}
+
+	s.constant, s.hasConstant = raw["const"]
+
+	for k, f := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+	} {
+		if *f, err = number(raw, k); err != nil {
+			return nil, err
+		}
+	}
+
+	for k, i := range map[string]**int{
+		"minLength": &s.minLength,
+		"maxLength": &s.maxLength,
+		"minItems":  &s.minItems,
+		"maxItems":  &s.maxItems,
+	} {
+		if *i, err = integer(raw, k); err != nil {
+			return nil, err
+		}
+	}
+
+	if p, ok := raw["pattern"]; ok {
+		ps, ok := p.(string)
+
+		if !ok {
+			return nil, invalidKeyword("pattern", p)
+		}
+
+		if s.pattern, err = regexp.Compile(ps); err != nil {
+			return nil, fmt.Errorf("jsonschema: invalid pattern '%v': %v", ps, err)
+		}
+	}
+
+	if s.allOf, err = compileList(raw, "allOf"); err != nil {
+		return nil, err
+	}
+
+	if s.anyOf, err = compileList(raw, "anyOf"); err != nil {
+		return nil, err
+	}
+
+	if s.oneOf, err = compileList(raw, "oneOf"); err != nil {
+		return nil, err
+	}
+
+	if n, ok := raw["not"]; ok {
+		if s.not, err = compileValue(n); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func compileList(raw map[string]interface{}, key string) ([]*schema, error) {
+	l, ok := raw[key]
+
+	if !ok {
+		return nil, nil
+	}
+
+	vs, ok := l.([]interface{})
+
+	if !ok || len(vs) == 0 {
+		return nil, invalidKeyword(key, l)
+	}
+
+	ss := make([]*schema, len(vs))
+
+	for i, v := range vs {
+		s, err := compileValue(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ss[i] = s
+	}
+
+	return ss, nil
+}
+
+func types(raw map[string]interface{}) ([]string, error) {
+	var ts []string
+
+	switch t := raw["type"].(type) {
+	case nil:
+		if _, ok := raw["type"]; ok {
+			return nil, invalidKeyword("type", t)
+		}
+
+		return nil, nil
+	case string:
+		ts = []string{t}
+	case []interface{}:
+		var err error
+
+		if ts, err = stringList(raw, "type"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, invalidKeyword("type", t)
+	}
+
+	for _, t := range ts {
+		if !typeNames[t] {
+			return nil, invalidKeyword("type", t)
+		}
+	}
+
+	return ts, nil
+}
+
+func stringList(raw map[string]interface{}, key string) ([]string, error) {
+	l, ok := raw[key]
+
+	if !ok {
+		return nil, nil
+	}
+
+	vs, ok := l.([]interface{})
+
+	if !ok {
+		return nil, invalidKeyword(key, l)
+	}
+
+	ss := make([]string, len(vs))
+
+	for i, v := range vs {
+		if ss[i], ok = v.(string); !ok {
+			return nil, invalidKeyword(key, l)
+		}
+	}
+
+	return ss, nil
+}
+
+func number(raw map[string]interface{}, key string) (*float64, error) {
+	v, ok := raw[key]
+
+	if !ok {
+		return nil, nil
+	}
+
+	f, ok := v.(float64)
+
+	if !ok {
+		return nil, invalidKeyword(key, v)
+	}
+
+	return &f, nil
+}
+
+func integer(raw map[string]interface{}, key string) (*int, error) {
+	v, ok := raw[key]
+
+	if !ok {
+		return nil, nil
+	}
+
+	f, ok := v.(float64)
+
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, invalidKeyword(key, v)
+	}
+
+	i := int(f)
+
+	return &i, nil
+}
+
+func invalidKeyword(key string, v interface{}) error {
+	return fmt.Errorf("jsonschema: invalid value for keyword '%v': %v", key, v)
+}
+
+func (s *schema) validate(path string, v interface{}, vs []Violation) []Violation {
+	if len(s.types) > 0 && !s.hasType(v) {
+		return append(vs, Violation{path, fmt.Sprintf("expected type %v, got %v", strings.Join(s.types, " or "), typeName(v))})
+	}
+
+	if len(s.enum) > 0 && !containsValue(s.enum, v) {
+		vs = append(vs, Violation{path, fmt.Sprintf("value %v is not one of %v", v, s.enum)})
+	}
+
+	if s.hasConstant && !reflect.DeepEqual(s.constant, v) {
+		vs = append(vs, Violation{path, fmt.Sprintf("value %v is not %v", v, s.constant)})
+	}
+
+	switch v := v.(type) {
+	case float64:
+		vs = s.validateNumber(path, v, vs)
+	case string:
+		vs = s.validateString(path, v, vs)
+	case []interface{}:
+		vs = s.validateArray(path, v, vs)
+	case map[string]interface{}:
+		vs = s.validateObject(path, v, vs)
+	}
+
+	for _, ss := range s.allOf {
+		vs = ss.validate(path, v, vs)
+	}
+
+	if len(s.anyOf) > 0 && s.matches(s.anyOf, v) == 0 {
+		vs = append(vs, Violation{path, "value matches none of anyOf"})
+	}
+
+	if len(s.oneOf) > 0 {
+		if n := s.matches(s.oneOf, v); n != 1 {
+			vs = append(vs, Violation{path, fmt.Sprintf("value matches %v of oneOf instead of exactly one", n)})
+		}
+	}
+
+	if s.not != nil && len(s.not.validate(path, v, nil)) == 0 {
+		vs = append(vs, Violation{path, "value must not match schema"})
+	}
+
+	return vs
+}
+
+func (s *schema) validateNumber(path string, v float64, vs []Violation) []Violation {
+	if s.minimum != nil && v < *s.minimum {
+		vs = append(vs, Violation{path, fmt.Sprintf("%v is less than minimum %v", v, *s.minimum)})
+	}
+
+	if s.maximum != nil && v > *s.maximum {
+		vs = append(vs, Violation{path, fmt.Sprintf("%v is greater than maximum %v", v, *s.maximum)})
+	}
+
+	if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+		vs = append(vs, Violation{path, fmt.Sprintf("%v is not greater than %v", v, *s.exclusiveMinimum)})
+	}
+
+	if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+		vs = append(vs, Violation{path, fmt.Sprintf("%v is not less than %v", v, *s.exclusiveMaximum)})
+	}
+
+	return vs
+}
+
+func (s *schema) validateString(path string, v string, vs []Violation) []Violation {
+	n := utf8.RuneCountInString(v)
+
+	if s.minLength != nil && n < *s.minLength {
+		vs = append(vs, Violation{path, fmt.Sprintf("length %v is less than %v", n, *s.minLength)})
+	}
+
+	if s.maxLength != nil && n > *s.maxLength {
+		vs = append(vs, Violation{path, fmt.Sprintf("length %v is greater than %v", n, *s.maxLength)})
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		vs = append(vs, Violation{path, fmt.Sprintf("'%v' does not match pattern '%v'", v, s.pattern)})
+	}
+
+	return vs
+}
+
+func (s *schema) validateArray(path string, v []interface{}, vs []Violation) []Violation {
+	if s.minItems != nil && len(v) < *s.minItems {
+		vs = append(vs, Violation{path, fmt.Sprintf("%v items are less than %v", len(v), *s.minItems)})
+	}
+
+	if s.maxItems != nil && len(v) > *s.maxItems {
+		vs = append(vs, Violation{path, fmt.Sprintf("%v items are more than %v", len(v), *s.maxItems)})
+	}
+
+	if s.items != nil {
+		for i, ev := range v {
+			vs = s.items.validate(path+"["+strconv.Itoa(i)+"]", ev, vs)
+		}
+	}
+
+	return vs
+}
+
+func (s *schema) validateObject(path string, v map[string]interface{}, vs []Violation) []Violation {
+	for _, r := range s.required {
+		if _, ok := v[r]; !ok {
+			vs = append(vs, Violation{path, fmt.Sprintf("missing required property '%v'", r)})
+		}
+	}
+
+	for k, pv := range v {
+		if ps, ok := s.properties[k]; ok {
+			vs = ps.validate(path+"."+k, pv, vs)
+		} else if s.noAdditional {
+			vs = append(vs, Violation{path, fmt.Sprintf("unexpected property '%v'", k)})
+		} else if s.additionalProperties != nil {
+			vs = s.additionalProperties.validate(path+"."+k, pv, vs)
+		}
+	}
+
+	return vs
+}
+
+func (s *schema) matches(ss []*schema, v interface{}) int {
+	n := 0
+
+	for _, sub := range ss {
+		if len(sub.validate("", v, nil)) == 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s *schema) hasType(v interface{}) bool {
+	for _, t := range s.types {
+		if t == typeName(v) || (t == "number" && typeName(v) == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeName(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func containsValue(vs []interface{}, v interface{}) bool {
+	for _, e := range vs {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+
+	return false
+}
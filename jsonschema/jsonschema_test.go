@@ -0,0 +1,137 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/jsonschema"
+)
+
+const userSchema = `{
+  "type": "object",
+  "required": ["id", "name"],
+  "properties": {
+    "id": {"type": "integer", "minimum": 1},
+    "name": {"type": "string", "minLength": 1}
+  },
+  "additionalProperties": false
+}`
+
+func TestValidatedExtractorAcceptsValidBody(t *testing.T) {
+	ex := jsonschema.ValidatedExtractor([]byte(userSchema), currly.PlainStringExtractor())
+	res, err := ex.Result(response(`{"id": 42, "name": "Bob"}`))
+
+	if err != nil {
+		t.Fatalf("Extracting a valid body returned an unexpected error: %v", err)
+	}
+
+	if `{"id": 42, "name": "Bob"}` != res {
+		t.Errorf("Unexpected result (expected: %v, actual: %v).", `{"id": 42, "name": "Bob"}`, res)
+	}
+}
+
+func TestValidatedExtractorRejectsContractViolations(t *testing.T) {
+	ex := jsonschema.ValidatedExtractor([]byte(userSchema), currly.PlainStringExtractor())
+	_, err := ex.Result(response(`{"id": 0, "nick": "Bob"}`))
+
+	var verr *jsonschema.ValidationError
+
+	if !errors.As(err, &verr) {
+		t.Fatalf("Extracting an invalid body should return a validation error, got: %v", err)
+	}
+
+	if 3 != len(verr.Violations) {
+		t.Errorf("Unexpected number of violations (expected: %v, actual: %v): %v", 3, len(verr.Violations), verr)
+	}
+}
+
+func TestValidatedExtractorAppliesKeywords(t *testing.T) {
+	cases := []struct {
+		schema     string
+		body       string
+		violations int
+	}{
+		{`{"enum": ["red", "green"]}`, `"red"`, 0},
+		{`{"enum": ["red", "green"]}`, `"blue"`, 1},
+		{`{"const": {"a": 1}}`, `{"a": 1}`, 0},
+		{`{"const": {"a": 1}}`, `{"a": 2}`, 1},
+		{`{"type": ["string", "null"]}`, `null`, 0},
+		{`{"type": "integer"}`, `1.5`, 1},
+		{`{"type": "number", "exclusiveMinimum": 0, "maximum": 10}`, `0`, 1},
+		{`{"type": "number", "exclusiveMinimum": 0, "maximum": 10}`, `11`, 1},
+		{`{"type": "string", "pattern": "^[a-z]+$", "maxLength": 3}`, `"abcd"`, 1},
+		{`{"type": "string", "pattern": "^[a-z]+$", "maxLength": 3}`, `"AB"`, 1},
+		{`{"items": {"type": "integer"}, "minItems": 1, "maxItems": 2}`, `[1, 2]`, 0},
+		{`{"items": {"type": "integer"}, "minItems": 1, "maxItems": 2}`, `["a", 2, "c"]`, 3},
+		{`{"items": {"type": "integer"}, "minItems": 1}`, `[]`, 1},
+		{`{"additionalProperties": {"type": "string"}}`, `{"a": "x", "b": 1}`, 1},
+		{`{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `3`, 0},
+		{`{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, 1},
+		{`{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1.5`, 0},
+		{`{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, 1},
+		{`{"allOf": [{"minimum": 1}, {"maximum": 3}]}`, `5`, 1},
+		{`{"not": {"type": "null"}}`, `null`, 1},
+		{`{"not": {"type": "null"}}`, `0`, 0},
+		{`false`, `0`, 1},
+		{`{"title": "any", "description": "annotations are ignored"}`, `0`, 0},
+	}
+
+	for _, c := range cases {
+		ex := jsonschema.ValidatedExtractor([]byte(c.schema), currly.PlainStringExtractor())
+		_, err := ex.Result(response(c.body))
+
+		var verr *jsonschema.ValidationError
+
+		switch {
+		case c.violations == 0 && err != nil:
+			t.Errorf("Validating %v against %v returned an unexpected error: %v", c.body, c.schema, err)
+		case c.violations > 0 && !errors.As(err, &verr):
+			t.Errorf("Validating %v against %v should fail, got: %v", c.body, c.schema, err)
+		case c.violations > 0 && c.violations != len(verr.Violations):
+			t.Errorf("Unexpected number of violations for %v against %v (expected: %v, actual: %v): %v", c.body, c.schema, c.violations, len(verr.Violations), verr)
+		}
+	}
+}
+
+func TestValidatedExtractorRejectsInvalidSchemas(t *testing.T) {
+	schemas := []string{
+		`{"$ref": "#/$defs/user", "$defs": {"user": {"type": "object"}}}`,
+		`{"type": "object", "patternProperties": {"^x-": {"type": "string"}}}`,
+		`{"type": "string", "format": "email"}`,
+		`{"type": "number", "multipleOf": 2}`,
+		`{"type": "array", "uniqueItems": true}`,
+		`{"properties": {"id": {"type": "uuid"}}}`,
+		`{"type": 42}`,
+		`{"required": "id"}`,
+		`{"minLength": -1}`,
+		`{"maximum": "10"}`,
+		`{"pattern": "("}`,
+		`{"enum": []}`,
+		`{"anyOf": {"type": "string"}}`,
+		`{"items": [{"type": "string"}]}`,
+		`[]`,
+		`{`,
+	}
+
+	for _, s := range schemas {
+		ex := jsonschema.ValidatedExtractor([]byte(s), currly.PlainStringExtractor())
+		_, err := ex.Result(response(`"value"`))
+
+		var verr *jsonschema.ValidationError
+
+		if err == nil || errors.As(err, &verr) {
+			t.Errorf("Compiling schema %v should fail, got: %v", s, err)
+		}
+	}
+}
+
+func response(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
@@ -0,0 +1,64 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+)
+
+func LabelArg(key, value string) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		if len(key) == 0 {
+			return errors.New("currly: label key must not be empty")
+		}
+
+		if ct.labels == nil {
+			ct.labels = make(map[string]string)
+		}
+
+		ct.labels[key] = value
+
+		return nil
+	})
+}
+
+func LabelsFromContext(ctx context.Context) map[string]string {
+	return copyLabels(callInfoFrom(ctx).labels)
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(labels))
+
+	for k, v := range labels {
+		m[k] = v
+	}
+
+	return m
+}
+
+func labelAttr(labels map[string]string) (slog.Attr, bool) {
+	if len(labels) == 0 {
+		return slog.Attr{}, false
+	}
+
+	keys := make([]string, 0, len(labels))
+
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	attrs := make([]interface{}, len(keys))
+
+	for i, k := range keys {
+		attrs[i] = slog.String(k, labels[k])
+	}
+
+	return slog.Group("labels", attrs...), true
+}
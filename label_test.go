@@ -0,0 +1,84 @@
+package currly_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestLabelsFlowToMiddlewareLogsAndResult(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	var seen map[string]string
+
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		seen = currly.LabelsFromContext(r.Context())
+
+		return okResponse(r, ""), nil
+	}), currly.LoggingMiddleware(slog.New(slog.NewJSONHandler(buf, nil))))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	_, _, err = curl(con, currly.LabelArg("feature", "search"), currly.LabelArg("tenant", "acme"), currly.ResultArg(&res))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "search" != seen["feature"] || "acme" != seen["tenant"] {
+		t.Errorf("Unexpected middleware labels: %v.", seen)
+	}
+
+	if "search" != res.Labels["feature"] || "acme" != res.Labels["tenant"] {
+		t.Errorf("Unexpected result labels: %v.", res.Labels)
+	}
+
+	var rec struct {
+		Labels map[string]string `json:"labels"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("The log record should be valid JSON: %v", err)
+	}
+
+	if "search" != rec.Labels["feature"] || "acme" != rec.Labels["tenant"] {
+		t.Errorf("Unexpected logged labels: %v.", rec.Labels)
+	}
+
+	if _, _, err = curl(con, currly.LabelArg("", "x")); err == nil {
+		t.Errorf("Calling the cURL function with an empty label key should fail.")
+	}
+}
+
+func TestLabelsAreCountedByStats(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	stats := new(currly.Stats)
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Stats(stats).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con, currly.LabelArg("tenant", "acme"))
+	curl(con, currly.LabelArg("tenant", "acme"), currly.LabelArg("feature", "search"))
+	curl(con, currly.LabelArg("tenant", "globex"))
+	curl(con)
+
+	expected := map[string]int64{"tenant=acme": 2, "tenant=globex": 1, "feature=search": 1}
+
+	if ss := stats.Snapshot(); 4 != ss.Count || !reflect.DeepEqual(expected, ss.Labels) {
+		t.Errorf("Unexpected labelled stats (expected: %v, actual: %v).", expected, ss.Labels)
+	}
+}
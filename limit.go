@@ -0,0 +1,86 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const (
+	QueueWhenSaturated SaturationPolicy = iota
+	FailWhenSaturated
+)
+
+var ErrTooManyInFlight = errors.New("currly: too many requests in flight")
+
+func HostConcurrencyMiddleware(n int, p SaturationPolicy) Middleware {
+	if n <= 0 {
+		return failingMiddleware("host-concurrency", fmt.Errorf("currly: invalid concurrency limit %v", n))
+	}
+
+	var mutex sync.Mutex
+
+	slots := make(map[string]chan struct{})
+
+	return Named("host-concurrency", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			mutex.Lock()
+			sem, ok := slots[r.URL.Host]
+
+			if !ok {
+				sem = make(chan struct{}, n)
+				slots[r.URL.Host] = sem
+			}
+
+			mutex.Unlock()
+
+			release, err := acquire(r.Context(), sem, p)
+
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next.Send(r)
+
+			return releaseOnClose(resp, err, release)
+		})
+	})
+}
+
+func releaseOnClose(resp *http.Response, err error, release func()) (*http.Response, error) {
+	if err != nil || resp.Body == nil {
+		release()
+
+		return resp, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+type SaturationPolicy int
+
+func acquire(ctx context.Context, sem chan struct{}, p SaturationPolicy) (func(), error) {
+	if sem == nil {
+		return func() {}, nil
+	}
+
+	if p == QueueWhenSaturated {
+		select {
+		case sem <- struct{}{}:
+			return func() { <-sem }, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	default:
+		return nil, ErrTooManyInFlight
+	}
+}
@@ -0,0 +1,144 @@
+package currly_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestMaxConcurrentFailsFastWhenSaturated(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		entered <- struct{}{}
+		<-release
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().MaxConcurrent(1).FailFastWhenSaturated().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		curl(con)
+	}()
+
+	<-entered
+
+	_, _, err = curl(con)
+
+	if currly.ErrTooManyInFlight != err {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrTooManyInFlight, err)
+	}
+
+	close(release)
+	wg.Wait()
+	go func() { <-entered }()
+
+	_, _, err = curl(con)
+
+	if err != nil {
+		t.Errorf("Calling the cURL function after the slot was released returned an unexpected error: %v", err)
+	}
+}
+
+func TestConcurrencyLimitsQueueOrFailFastWhenSaturated(t *testing.T) {
+	for _, p := range []currly.SaturationPolicy{currly.QueueWhenSaturated, currly.FailWhenSaturated} {
+		entered := make(chan struct{}, 2)
+		release := make(chan struct{})
+		backend := connectorFunc(func(r *http.Request) (*http.Response, error) {
+			entered <- struct{}{}
+			<-release
+
+			return okResponse(r, ""), nil
+		})
+		host := currly.Wrap(backend, currly.HostConcurrencyMiddleware(1, p))
+		plain, _ := currly.Builder().GET().HTTPS().Localhost().Build()
+		template := currly.Builder().GET().HTTPS().Localhost().MaxConcurrent(1)
+
+		if currly.FailWhenSaturated == p {
+			template = template.FailFastWhenSaturated()
+		}
+
+		limited, _ := template.Build()
+
+		for _, c := range []struct {
+			curl currly.CurlFunc
+			con  currly.Connector
+		}{{plain, host}, {limited, backend}} {
+			errs := make(chan error, 2)
+
+			for i := 0; i < 2; i++ {
+				go func() {
+					_, _, err := c.curl(c.con)
+					errs <- err
+				}()
+			}
+
+			<-entered
+
+			if currly.FailWhenSaturated == p {
+				if err := <-errs; currly.ErrTooManyInFlight != err {
+					t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrTooManyInFlight, err)
+				}
+
+				release <- struct{}{}
+			} else {
+				release <- struct{}{}
+				<-entered
+				release <- struct{}{}
+
+				if err := <-errs; err != nil {
+					t.Errorf("A queued call returned an unexpected error: %v", err)
+				}
+			}
+
+			if err := <-errs; err != nil {
+				t.Errorf("Calling the cURL function returned an unexpected error: %v", err)
+			}
+		}
+	}
+}
+
+func TestHostConcurrencyMiddlewareRejectsInvalidLimits(t *testing.T) {
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	}), currly.HostConcurrencyMiddleware(0, currly.QueueWhenSaturated))
+	curl, _ := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if _, _, err := curl(con); err == nil {
+		t.Errorf("A concurrency limit of 0 should be rejected.")
+	}
+}
+
+func TestHostConcurrencyMiddlewareHoldsSlotsUntilBodiesAreClosed(t *testing.T) {
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, "payload"), nil
+	}), currly.HostConcurrencyMiddleware(1, currly.FailWhenSaturated))
+	r, _ := http.NewRequest(http.MethodGet, "https://localhost/stream", nil)
+	resp, err := con.Send(r)
+
+	if err != nil {
+		t.Fatalf("Sending the request returned an unexpected error: %v", err)
+	}
+
+	if _, err := con.Send(r); currly.ErrTooManyInFlight != err {
+		t.Errorf("Unexpected error while a body is open (expected: %v, actual: %v).", currly.ErrTooManyInFlight, err)
+	}
+
+	resp.Body.Close()
+
+	if _, err := con.Send(r); err != nil {
+		t.Errorf("Sending the request after closing the body returned an unexpected error: %v", err)
+	}
+}
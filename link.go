@@ -0,0 +1,215 @@
+package currly
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+func ParseLinkHeader(h http.Header) (LinkHeader, error) {
+	var links LinkHeader
+
+	for _, v := range h.Values("Link") {
+		ls, err := parseLinks(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		links = append(links, ls...)
+	}
+
+	return links, nil
+}
+
+func (r Result) Links() (LinkHeader, error) {
+	links, err := ParseLinkHeader(r.Header)
+
+	if err != nil || r.URL == nil {
+		return links, err
+	}
+
+	for i, l := range links {
+		u, err := r.URL.Parse(l.URL)
+
+		if err != nil {
+			return nil, fmt.Errorf("currly: link target '%v' is invalid: %w", l.URL, err)
+		}
+
+		links[i].URL = u.String()
+	}
+
+	return links, nil
+}
+
+func (lh LinkHeader) Rel(rel string) (Link, bool) {
+	for _, l := range lh {
+		for _, r := range strings.Fields(l.Rel) {
+			if strings.EqualFold(r, rel) {
+				return l, true
+			}
+		}
+	}
+
+	return Link{}, false
+}
+
+func (lh LinkHeader) Next() (Link, bool) {
+	return lh.Rel("next")
+}
+
+func (lh LinkHeader) Prev() (Link, bool) {
+	if l, ok := lh.Rel("prev"); ok {
+		return l, true
+	}
+
+	return lh.Rel("previous")
+}
+
+func (lh LinkHeader) First() (Link, bool) {
+	return lh.Rel("first")
+}
+
+func (lh LinkHeader) Last() (Link, bool) {
+	return lh.Rel("last")
+}
+
+func parseLinks(s string) (LinkHeader, error) {
+	var links LinkHeader
+
+	p := linkParser{s: s}
+
+	for {
+		p.skip(" \t,")
+
+		if p.done() {
+			return links, nil
+		}
+
+		if !p.consume('<') {
+			return nil, p.errorf("expected '<'")
+		}
+
+		end := strings.IndexByte(p.s[p.i:], '>')
+
+		if end < 0 {
+			return nil, p.errorf("unterminated link target")
+		}
+
+		l := Link{URL: strings.TrimSpace(p.s[p.i : p.i+end]), Params: make(map[string]string)}
+		p.i += end + 1
+
+		for {
+			p.skip(" \t")
+
+			if !p.consume(';') {
+				break
+			}
+
+			p.skip(" \t")
+
+			name := strings.ToLower(p.token())
+
+			if len(name) == 0 {
+				return nil, p.errorf("missing parameter name")
+			}
+
+			p.skip(" \t")
+
+			value := ""
+
+			if p.consume('=') {
+				p.skip(" \t")
+
+				var err error
+
+				if value, err = p.value(); err != nil {
+					return nil, err
+				}
+			}
+
+			if _, ok := l.Params[name]; !ok {
+				l.Params[name] = value
+			}
+		}
+
+		if !p.done() && p.s[p.i] != ',' {
+			return nil, p.errorf("expected ',' or ';'")
+		}
+
+		l.Rel = l.Params["rel"]
+		links = append(links, l)
+	}
+}
+
+type LinkHeader []Link
+
+type Link struct {
+	URL    string
+	Rel    string
+	Params map[string]string
+}
+
+type linkParser struct {
+	s string
+	i int
+}
+
+func (p *linkParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *linkParser) skip(chars string) {
+	for !p.done() && strings.IndexByte(chars, p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+func (p *linkParser) consume(c byte) bool {
+	if p.done() || p.s[p.i] != c {
+		return false
+	}
+
+	p.i++
+
+	return true
+}
+
+func (p *linkParser) token() string {
+	start := p.i
+
+	for !p.done() && strings.IndexByte(" \t;,=\"", p.s[p.i]) < 0 {
+		p.i++
+	}
+
+	return p.s[start:p.i]
+}
+
+func (p *linkParser) value() (string, error) {
+	if !p.consume('"') {
+		return p.token(), nil
+	}
+
+	var b strings.Builder
+
+	for !p.done() {
+		c := p.s[p.i]
+		p.i++
+
+		switch {
+		case c == '"':
+			return b.String(), nil
+		case c == '\\' && !p.done():
+			b.WriteByte(p.s[p.i])
+			p.i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated quoted string")
+}
+
+func (p *linkParser) errorf(reason string) error {
+	return fmt.Errorf("currly: malformed Link header '%v' at offset %v: %v", p.s, p.i, reason)
+}
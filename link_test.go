@@ -0,0 +1,85 @@
+package currly_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	h := http.Header{}
+	h.Add("Link", `<https://api.example.com/repos?page=3>; rel="next", <https://api.example.com/repos?page=1>; rel="prev first"`)
+	h.Add("Link", `<https://api.example.com/repos?page=9>; rel=last; title="Last, \"final\" page"`)
+
+	links, err := currly.ParseLinkHeader(h)
+
+	if err != nil {
+		t.Fatalf("Parsing the Link header returned an unexpected error: %v", err)
+	}
+
+	if 3 != len(links) {
+		t.Fatalf("Unexpected number of links (expected: 3, actual: %v).", len(links))
+	}
+
+	cases := []struct {
+		find     func() (currly.Link, bool)
+		expected string
+	}{
+		{links.Next, "https://api.example.com/repos?page=3"},
+		{links.Prev, "https://api.example.com/repos?page=1"},
+		{links.First, "https://api.example.com/repos?page=1"},
+		{links.Last, "https://api.example.com/repos?page=9"},
+	}
+
+	for i, c := range cases {
+		if l, ok := c.find(); !ok || c.expected != l.URL {
+			t.Errorf("Unexpected link in case %v (expected: %v, actual: %v).", i, c.expected, l.URL)
+		}
+	}
+
+	if "Last, \"final\" page" != links[2].Params["title"] {
+		t.Errorf("Unexpected link parameter (expected: %v, actual: %v).", "Last, \"final\" page", links[2].Params["title"])
+	}
+}
+
+func TestParseLinkHeaderRejectsMalformedValues(t *testing.T) {
+	for _, v := range []string{`https://example.com; rel=next`, `<https://example.com; rel=next`, `<https://example.com>; rel="next`, `<a> x`} {
+		h := http.Header{}
+		h.Set("Link", v)
+
+		if _, err := currly.ParseLinkHeader(h); err == nil {
+			t.Errorf("Parsing '%v' should fail.", v)
+		}
+	}
+}
+
+func TestResultLinksResolveAgainstRequestURL(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, "")
+		resp.Header.Set("Link", `</repos?page=2>; rel="next"`)
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Host("api.example.com").PathSegment("repos").ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	if _, _, err = curl(con, currly.ResultArg(&res)); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	links, err := res.Links()
+
+	if err != nil {
+		t.Fatalf("Resolving the result links returned an unexpected error: %v", err)
+	}
+
+	if l, ok := links.Next(); !ok || "https://api.example.com/repos?page=2" != l.URL {
+		t.Errorf("Unexpected next link (expected: %v, actual: %v).", "https://api.example.com/repos?page=2", l.URL)
+	}
+}
@@ -0,0 +1,173 @@
+package currly
+
+import (
+	"errors"
+	"net/http"
+)
+
+func Endpoint(method string) BuildPath {
+	return curlTemplate{method: method}
+}
+
+func Merge(base, endpoint BuildCurl) SetResultExtractor {
+	b, ok := base.(curlTemplate)
+
+	if !ok {
+		return curlTemplate{error: errors.New("currly: base template was not created by currly")}
+	}
+
+	e, ok := endpoint.(curlTemplate)
+
+	if !ok {
+		return curlTemplate{error: errors.New("currly: endpoint template was not created by currly")}
+	}
+
+	if b.error != nil {
+		return b
+	}
+
+	if e.error != nil {
+		return e
+	}
+
+	return mergeTemplates(b, e)
+}
+
+func mergeTemplates(b, e curlTemplate) curlTemplate {
+	m := b
+
+	m.method = orString(e.method, b.method)
+	m.urlTemplate = mergeURLTemplates(b.urlTemplate, e.urlTemplate)
+	m.header = copyHeader(b.header)
+
+	for k, v := range e.header {
+		m.header[k] = append([]string(nil), v...)
+	}
+
+	if e.credentials != emptyCredentials {
+		m.credentials = e.credentials
+	}
+
+	if e.credentialProvider != nil {
+		m.credentialProvider = e.credentialProvider
+	}
+
+	if e.refreshAuth != nil {
+		m.refreshAuth = e.refreshAuth
+	}
+
+	m.templateName = orString(e.templateName, b.templateName)
+	m.version = orString(e.version, b.version)
+	m.deterministic = b.deterministic || e.deterministic
+
+	if e.deprecation != nil {
+		m.deprecation = e.deprecation
+	}
+
+	m.hostHeader = orString(e.hostHeader, b.hostHeader)
+	m.tlsServerName = orString(e.tlsServerName, b.tlsServerName)
+
+	if e.body != nil {
+		m.body = e.body
+		m.contentLength = e.contentLength
+	}
+
+	if e.defaultBody != nil {
+		m.defaultBody = e.defaultBody
+		m.bodyMerge = e.bodyMerge
+	}
+
+	if e.idempotency != methodIdempotency {
+		m.idempotency = e.idempotency
+	}
+
+	if len(e.acceptStatus) > 0 {
+		m.acceptStatus = append([]int(nil), e.acceptStatus...)
+	}
+
+	if e.resultExtractor != nil {
+		m.resultExtractor = e.resultExtractor
+	}
+
+	if e.stats != nil {
+		m.stats = e.stats
+	}
+
+	if e.redaction != nil {
+		m.redaction = e.redaction
+	}
+
+	m.beforeSend = append(append([]func(r *http.Request) error(nil), b.beforeSend...), e.beforeSend...)
+	m.afterReceive = append(append([]func(r *http.Response) error(nil), b.afterReceive...), e.afterReceive...)
+	m.processors = append(append([]ResponseProcessor(nil), b.processors...), e.processors...)
+	m.mappers = append(append([]ResultMapper(nil), b.mappers...), e.mappers...)
+	m.bindHooks = append(append(([]func(e BindEvent))(nil), b.bindHooks...), e.bindHooks...)
+	m.middleware = append(append([]Middleware(nil), b.middleware...), e.middleware...)
+
+	if e.errorBodyLimit != 0 {
+		m.errorBodyLimit = e.errorBodyLimit
+	}
+
+	if e.timeout > 0 {
+		m.timeout = e.timeout
+	}
+
+	if e.connectTimeout > 0 {
+		m.connectTimeout = e.connectTimeout
+	}
+
+	if e.headerTimeout > 0 {
+		m.headerTimeout = e.headerTimeout
+	}
+
+	if e.bodyTimeout > 0 {
+		m.bodyTimeout = e.bodyTimeout
+	}
+
+	if e.inFlight != nil {
+		m.inFlight = e.inFlight
+	}
+
+	if e.saturation != QueueWhenSaturated {
+		m.saturation = e.saturation
+	}
+
+	if e.clock != nil {
+		m.clock = e.clock
+	}
+
+	return m
+}
+
+func mergeURLTemplates(b, e urlTemplate) urlTemplate {
+	m := b
+
+	m.scheme = orString(e.scheme, b.scheme)
+	m.host = orString(e.host, b.host)
+
+	if e.port > 0 {
+		m.port = e.port
+	}
+
+	if e.basePath != nil {
+		m.basePath = e.basePath
+	}
+
+	m.path = append(append([]variable(nil), b.path...), e.path...)
+	m.query = append(append([]variable(nil), b.query...), e.query...)
+	m.sortQuery = b.sortQuery || e.sortQuery
+
+	if e.encoding != nil {
+		m.encoding = e.encoding
+	}
+
+	return m
+}
+
+func orString(s, fallback string) string {
+	if len(s) > 0 {
+		return s
+	}
+
+	return fallback
+}
@@ -0,0 +1,95 @@
+package currly_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestMergeCombinesBaseAndEndpoint(t *testing.T) {
+	var req *http.Request
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	})
+	base := currly.Builder().GET().HTTPS().Host("api.example.com").
+		PathSegment("v1").
+		Header(http.Header{"Accept": {"application/json"}, "X-Tenant": {"default"}}).
+		Credentials("user", "secret")
+	endpoint := currly.Endpoint(http.MethodPost).
+		PathSegment("users").
+		PathParam("id").
+		QueryParam("notify").
+		Header(http.Header{"X-Tenant": {"acme"}})
+	curl, err := currly.Merge(base, endpoint).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con, currly.PathArg("id", "42"), currly.QueryArg("notify", "true")); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "https://api.example.com/v1/users/42?notify=true" != req.URL.String() {
+		t.Errorf("Unexpected URL (expected: %v, actual: %v).", "https://api.example.com/v1/users/42?notify=true", req.URL)
+	}
+
+	if http.MethodPost != req.Method {
+		t.Errorf("Unexpected request method (expected: %v, actual: %v).", http.MethodPost, req.Method)
+	}
+
+	if "acme" != req.Header.Get("X-Tenant") || "application/json" != req.Header.Get("Accept") {
+		t.Errorf("Unexpected headers (actual: %v).", req.Header)
+	}
+
+	if u, p, ok := req.BasicAuth(); !ok || "user" != u || "secret" != p {
+		t.Errorf("The base credentials should be applied.")
+	}
+
+	if _, err := currly.Endpoint(http.MethodGet).PathSegment("users").Build(); err == nil {
+		t.Errorf("Building an endpoint fragment without a base should fail.")
+	}
+}
+
+func TestBasePathPrefixesDerivedTemplates(t *testing.T) {
+	var paths []string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		paths = append(paths, r.URL.Path)
+
+		return okResponse(r, ""), nil
+	})
+	base := currly.Builder().GET().HTTPS().Localhost().BasePath("/api/v2/")
+	users, err := currly.Merge(base, currly.Endpoint(http.MethodGet).PathSegment("users").PathParam("id")).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	calls := [][]currly.Arg{
+		{currly.PathArg("id", "42")},
+		{currly.PathArg("id", "42"), currly.BasePathArg("gateway/api")},
+		{currly.BasePathArg("")},
+	}
+
+	for _, args := range calls {
+		if _, _, err = users(con, args...); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{"/api/v2/users/42", "/gateway/api/users/42", "/users"}
+
+	if fmt.Sprint(expected) != fmt.Sprint(paths) {
+		t.Errorf("Unexpected paths (expected: %v, actual: %v).", expected, paths)
+	}
+
+	if _, _, err = users(con, currly.BasePathArg("api/v?")); err == nil {
+		t.Errorf("Expected an error for an invalid base path.")
+	}
+}
@@ -0,0 +1,86 @@
+package currly
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func ParseMetadata(h http.Header, now time.Time) ResponseMetadata {
+	var m ResponseMetadata
+
+	if d, err := http.ParseTime(h.Get("Date")); err == nil {
+		now = d
+	}
+
+	m.RateLimit.Limit, m.RateLimit.Known = headerInt(h, "X-RateLimit-Limit", "RateLimit-Limit")
+
+	if n, ok := headerInt(h, "X-RateLimit-Remaining", "RateLimit-Remaining"); ok {
+		m.RateLimit.Remaining = n
+		m.RateLimit.Known = true
+	}
+
+	if n, ok := headerInt(h, "X-RateLimit-Reset", "RateLimit-Reset"); ok {
+		if n > 1e9 {
+			m.RateLimit.Reset = time.Unix(int64(n), 0)
+		} else {
+			m.RateLimit.Reset = now.Add(time.Duration(n) * time.Second)
+		}
+
+		m.RateLimit.Known = true
+	}
+
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+
+		if !strings.EqualFold(name, "max-age") {
+			continue
+		}
+
+		if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && n >= 0 {
+			m.MaxAge = time.Duration(n) * time.Second
+			m.HasMaxAge = true
+		}
+	}
+
+	if n, ok := headerInt(h, "Age"); ok && n >= 0 {
+		m.Age = time.Duration(n) * time.Second
+	}
+
+	m.ETag = h.Get("ETag")
+
+	return m
+}
+
+type ResponseMetadata struct {
+	RateLimit RateLimitStatus
+	MaxAge    time.Duration
+	HasMaxAge bool
+	Age       time.Duration
+	ETag      string
+	ServedBy  string
+}
+
+type RateLimitStatus struct {
+	Known     bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+func (m ResponseMetadata) Fresh() bool {
+	return m.HasMaxAge && m.Age < m.MaxAge
+}
+
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		if v := h.Get(name); len(v) > 0 {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+
+			return n, err == nil
+		}
+	}
+
+	return 0, false
+}
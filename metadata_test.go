@@ -0,0 +1,76 @@
+package currly_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestParseMetadataReadsCacheAndRateLimitHeaders(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := currly.ParseMetadata(http.Header{
+		"X-Ratelimit-Limit":     {"100"},
+		"X-Ratelimit-Remaining": {"7"},
+		"X-Ratelimit-Reset":     {"30"},
+		"Cache-Control":         {"public, max-age=60"},
+		"Age":                   {"10"},
+		"Etag":                  {`"v1"`},
+	}, now)
+
+	if !m.RateLimit.Known || 100 != m.RateLimit.Limit || 7 != m.RateLimit.Remaining {
+		t.Errorf("Unexpected rate limit status: %+v.", m.RateLimit)
+	}
+
+	if !now.Add(30 * time.Second).Equal(m.RateLimit.Reset) {
+		t.Errorf("Unexpected rate limit reset (expected: %v, actual: %v).", now.Add(30*time.Second), m.RateLimit.Reset)
+	}
+
+	if !m.HasMaxAge || time.Minute != m.MaxAge || 10*time.Second != m.Age || !m.Fresh() {
+		t.Errorf("Unexpected cache metadata: %+v.", m)
+	}
+
+	if `"v1"` != m.ETag {
+		t.Errorf("Unexpected ETag (expected: %v, actual: %v).", `"v1"`, m.ETag)
+	}
+
+	m = currly.ParseMetadata(http.Header{
+		"Ratelimit-Reset": {"1700000000"},
+		"Cache-Control":   {"no-store"},
+		"Age":             {"soon"},
+	}, now)
+
+	if !m.RateLimit.Known || !time.Unix(1700000000, 0).Equal(m.RateLimit.Reset) {
+		t.Errorf("Unexpected rate limit status: %+v.", m.RateLimit)
+	}
+
+	if m.HasMaxAge || 0 != m.Age || m.Fresh() {
+		t.Errorf("Unexpected cache metadata: %+v.", m)
+	}
+}
+
+func TestResultArgCapturesMetadata(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, "")
+		resp.Header.Set("ETag", `"abc"`)
+		resp.Header.Set("X-RateLimit-Remaining", "0")
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	if _, _, err = curl(con, currly.ResultArg(&res)); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if `"abc"` != res.Metadata.ETag || !res.Metadata.RateLimit.Known || 0 != res.Metadata.RateLimit.Remaining {
+		t.Errorf("Unexpected result metadata: %+v.", res.Metadata)
+	}
+}
@@ -0,0 +1,205 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func Wrap(con Connector, mws ...Middleware) Connector {
+	cc := &chainConnector{Connector: con, names: make([]string, len(mws)), next: con}
+
+	for i := len(mws) - 1; i >= 0; i-- {
+		cc.Connector = mws[i](cc.Connector)
+
+		if nc, ok := cc.Connector.(*namedConnector); ok {
+			cc.names[i] = nc.name
+		} else {
+			cc.names[i] = middlewareName(mws[i])
+		}
+	}
+
+	return cc
+}
+
+func Named(name string, mw Middleware) Middleware {
+	return func(next Connector) Connector {
+		return &namedConnector{Connector: mw(next), name: name}
+	}
+}
+
+func Chain(con Connector) []string {
+	var names []string
+
+	for {
+		cc, ok := con.(*chainConnector)
+
+		if !ok {
+			return names
+		}
+
+		names = append(names, cc.names...)
+		con = cc.next
+	}
+}
+
+func ChainFromContext(ctx context.Context) []string {
+	return callInfoFrom(ctx).chain
+}
+
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return Named("logging", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Send(r)
+			ci := callInfoFrom(r.Context())
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("url_template", ci.urlTemplate),
+				slog.String("url", RedactionPolicyFromContext(r.Context()).URL(r.URL)),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("attempt", ci.attempt),
+			}
+			level := slog.LevelInfo
+
+			if ci.deprecation != nil && ci.deprecation.warn() {
+				logger.LogAttrs(r.Context(), slog.LevelWarn, "currly deprecated template", ci.deprecationAttrs()...)
+			}
+
+			if len(ci.version) > 0 {
+				attrs = append(attrs, slog.String("api_version", ci.version))
+			}
+
+			if a, ok := labelAttr(ci.labels); ok {
+				attrs = append(attrs, a)
+			}
+
+			if id, ok := CorrelationIDFromContext(r.Context()); ok {
+				attrs = append(attrs, slog.String("correlation_id", id))
+			}
+
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				level = slog.LevelError
+			} else {
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+				if resp.StatusCode >= 400 {
+					level = slog.LevelWarn
+				}
+			}
+
+			logger.LogAttrs(r.Context(), level, "currly request", attrs...)
+
+			return resp, err
+		})
+	})
+}
+
+func DefaultHeaderMiddleware(header http.Header) Middleware {
+	return Named("default-header", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+
+			for k, v := range header {
+				if _, ok := r.Header[k]; !ok {
+					r.Header[k] = append([]string(nil), v...)
+				}
+			}
+
+			return next.Send(r)
+		})
+	})
+}
+
+type ConnectorFunc func(r *http.Request) (*http.Response, error)
+
+type Middleware func(next Connector) Connector
+
+type namedConnector struct {
+	Connector
+	name string
+}
+
+type callConnectorKey struct{}
+
+type chainConnector struct {
+	Connector
+	names []string
+	next  Connector
+}
+
+type callInfo struct {
+	urlTemplate  string
+	attempt      int
+	redaction    *RedactionPolicy
+	chain        []string
+	idempotency  idempotency
+	templateName string
+	version      string
+	deprecation  *deprecation
+	labels       map[string]string
+}
+
+type callInfoKey struct{}
+
+func (f ConnectorFunc) Send(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func withCallInfo(ctx context.Context, ci callInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, ci)
+}
+
+func callInfoFrom(ctx context.Context) callInfo {
+	ci, ok := ctx.Value(callInfoKey{}).(callInfo)
+
+	if !ok {
+		ci.attempt = 1
+	}
+
+	return ci
+}
+
+func failingMiddleware(name string, err error) Middleware {
+	return Named(name, func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, err
+		})
+	})
+}
+
+func templateChain(mws []Middleware) Connector {
+	if len(mws) == 0 {
+		return nil
+	}
+
+	return Wrap(ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+		con, ok := r.Context().Value(callConnectorKey{}).(Connector)
+
+		if !ok {
+			return nil, errors.New("currly: request context does not carry the call connector")
+		}
+
+		return con.Send(r)
+	}), mws...)
+}
+
+func middlewareName(mw Middleware) string {
+	name := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()).Name()
+
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
+}
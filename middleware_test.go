@@ -0,0 +1,175 @@
+package currly_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestLoggingMiddlewareEmitsStructuredAttributes(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	}), currly.LoggingMiddleware(logger))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathSegment("users").PathParam("id").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con, currly.PathArg("id", "42"))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	var rec map[string]interface{}
+
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("The log record should be valid JSON: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"level":        "INFO",
+		"method":       http.MethodGet,
+		"url_template": "https://localhost/users/{id}",
+		"status":       float64(http.StatusOK),
+		"attempt":      float64(1),
+	}
+
+	for k, v := range expected {
+		if v != rec[k] {
+			t.Errorf("Unexpected log attribute '%v' (expected: %v, actual: %v).", k, v, rec[k])
+		}
+	}
+}
+
+func TestDefaultHeaderMiddlewareMergesBeneathTemplateHeaders(t *testing.T) {
+	var req *http.Request
+
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+
+		return okResponse(r, ""), nil
+	}), currly.DefaultHeaderMiddleware(http.Header{
+		"User-Agent": {"currly/1.0"},
+		"X-Tenant":   {"default"},
+	}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Header(http.Header{"X-Tenant": {"acme"}}).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "currly/1.0" != req.Header.Get("User-Agent") {
+		t.Errorf("Unexpected User-Agent header (expected: %v, actual: %v).", "currly/1.0", req.Header.Get("User-Agent"))
+	}
+
+	if "acme" != req.Header.Get("X-Tenant") {
+		t.Errorf("Unexpected X-Tenant header (expected: %v, actual: %v).", "acme", req.Header.Get("X-Tenant"))
+	}
+}
+
+func TestTemplateMiddlewareRunsBeforeConnectorMiddleware(t *testing.T) {
+	var order []string
+	var chain []string
+
+	trace := func(name string) currly.Middleware {
+		return currly.Named(name, func(next currly.Connector) currly.Connector {
+			return currly.ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+
+				return next.Send(r)
+			})
+		})
+	}
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		chain = currly.ChainFromContext(r.Context())
+
+		return okResponse(r, ""), nil
+	}), trace("connector-1"), trace("connector-2"))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		Use(trace("template-1")).
+		Use(currly.DefaultHeaderMiddleware(http.Header{"Accept": {"application/json"}})).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err := curl(con); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	expected := []string{"template-1", "connector-1", "connector-2"}
+
+	if fmt.Sprint(expected) != fmt.Sprint(order) {
+		t.Errorf("Unexpected execution order (expected: %v, actual: %v).", expected, order)
+	}
+
+	names := []string{"template-1", "default-header", "connector-1", "connector-2"}
+
+	if fmt.Sprint(names) != fmt.Sprint(chain) {
+		t.Errorf("Unexpected effective chain (expected: %v, actual: %v).", names, chain)
+	}
+
+	if names := currly.Chain(con); "[connector-1 connector-2]" != fmt.Sprint(names) {
+		t.Errorf("Unexpected connector chain (expected: %v, actual: %v).", "[connector-1 connector-2]", names)
+	}
+}
+
+func TestTemplateMiddlewareIsBuiltOnce(t *testing.T) {
+	var builds int
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		Use(func(next currly.Connector) currly.Connector {
+			builds++
+
+			return next
+		}).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := curl(con); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+	}
+
+	if 1 != builds {
+		t.Errorf("Unexpected number of chain constructions (expected: %v, actual: %v).", 1, builds)
+	}
+}
+
+func TestChainNamesUnnamedMiddlewareByFunction(t *testing.T) {
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	}), currly.LoggingMiddleware(slog.Default()), passThrough)
+	expected := "[logging currly_test.passThrough]"
+
+	if names := currly.Chain(con); expected != fmt.Sprint(names) {
+		t.Errorf("Unexpected connector chain (expected: %v, actual: %v).", expected, names)
+	}
+}
+
+func passThrough(next currly.Connector) currly.Connector {
+	return next
+}
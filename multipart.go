@@ -0,0 +1,127 @@
+package currly
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+func MultipartBodyArg(parts ...FormPart) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		if len(parts) == 0 {
+			return errors.New("currly: multipart body must have at least one part")
+		}
+
+		buf := new(bytes.Buffer)
+		w := multipart.NewWriter(buf)
+
+		if ct.deterministic {
+			if err := w.SetBoundary(multipartBoundary(parts)); err != nil {
+				return err
+			}
+		}
+
+		for _, p := range parts {
+			if len(p.Name) == 0 {
+				return errors.New("currly: multipart part name must not be empty")
+			}
+
+			pw, err := w.CreatePart(p.header())
+
+			if err != nil {
+				return err
+			}
+
+			if _, err = pw.Write(p.Data); err != nil {
+				return err
+			}
+		}
+
+		if err := w.Close(); err != nil {
+			return err
+		}
+
+		if ct.header == nil {
+			ct.header = make(http.Header)
+		}
+
+		ct.header.Set("Content-Type", w.FormDataContentType())
+		ct.body = &multipartBody{bytes.NewReader(buf.Bytes()), buf.Bytes()}
+		ct.contentLength = int64(buf.Len())
+
+		return nil
+	})
+}
+
+func (ct curlTemplate) Deterministic() SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.deterministic = true
+	ct.urlTemplate.sortQuery = true
+
+	return ct
+}
+
+func multipartBoundary(parts []FormPart) string {
+	h := sha256.New()
+
+	for _, p := range parts {
+		for _, s := range []string{p.Name, p.FileName, p.ContentType, string(p.Data)} {
+			fmt.Fprintf(h, "%d:%s", len(s), s)
+		}
+	}
+
+	return "currly-" + hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+type FormPart struct {
+	Name        string
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+type multipartBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (p FormPart) header() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(p.Name))
+
+	if len(p.FileName) > 0 {
+		disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(p.FileName))
+	}
+
+	h.Set("Content-Disposition", disposition)
+
+	if len(p.ContentType) > 0 {
+		h.Set("Content-Type", p.ContentType)
+	} else if len(p.FileName) > 0 {
+		h.Set("Content-Type", "application/octet-stream")
+	}
+
+	return h
+}
+
+func (mb *multipartBody) Close() error {
+	return nil
+}
+
+func (mb *multipartBody) replay() io.ReadCloser {
+	return &multipartBody{bytes.NewReader(mb.data), mb.data}
+}
+
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
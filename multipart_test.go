@@ -0,0 +1,114 @@
+package currly_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestDeterministicMultipartBodies(t *testing.T) {
+	var bodies, types, hashes []string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		hash, err := currly.RequestHash(r, "Date")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			return nil, err
+		}
+
+		if "Bob" != r.FormValue("name") {
+			t.Errorf("Unexpected form value (expected: %v, actual: %v).", "Bob", r.FormValue("name"))
+		}
+
+		if _, fh, err := r.FormFile("avatar"); err != nil || "me.png" != fh.Filename || "image/png" != fh.Header.Get("Content-Type") {
+			t.Errorf("Unexpected form file (header: %v, error: %v).", fh, err)
+		}
+
+		body, _ := r.GetBody()
+		bs, _ := ioutil.ReadAll(body)
+		bodies, types, hashes = append(bodies, string(bs)), append(types, r.Header.Get("Content-Type")), append(hashes, hash)
+
+		return okResponse(r, ""), nil
+	})
+	parts := []currly.FormPart{
+		{Name: "name", Data: []byte("Bob")},
+		{Name: "avatar", FileName: "me.png", ContentType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}},
+	}
+
+	for _, deterministic := range []bool{true, false} {
+		bodies, types, hashes = nil, nil, nil
+
+		var build currly.SetResultExtractor = currly.Builder().POST().HTTPS().Localhost().PathSegment("users").QueryParam("b").QueryParam("a")
+
+		if deterministic {
+			build = build.Deterministic()
+		}
+
+		curl, err := build.ResultExtractor(currly.PlainStringExtractor()).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, _, err := curl(con, currly.MultipartBodyArg(parts...), currly.QueryArg("a", "1"), currly.QueryArg("b", "2")); err != nil {
+				t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+			}
+		}
+
+		same := bodies[0] == bodies[1] && types[0] == types[1] && hashes[0] == hashes[1]
+
+		if deterministic != same {
+			t.Errorf("Unexpected serialization (deterministic: %v, content types: %v).", deterministic, types)
+		}
+
+		if deterministic && !strings.Contains(types[0], "boundary=currly-") {
+			t.Errorf("Unexpected multipart boundary: %v.", types[0])
+		}
+	}
+}
+
+func TestRequestHashIsStable(t *testing.T) {
+	newRequest := func(url, body string, header map[string]string) *http.Request {
+		r, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+
+		if err != nil {
+			t.Fatalf("Creating the request returned an unexpected error: %v", err)
+		}
+
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+
+		return r
+	}
+	hash := func(r *http.Request) string {
+		h, err := currly.RequestHash(r, "Date", "X-Request-Id")
+
+		if err != nil {
+			t.Fatalf("Hashing the request returned an unexpected error: %v", err)
+		}
+
+		return h
+	}
+	base := newRequest("https://API.example.com/users?b=2&a=1", `{"id":1}`, map[string]string{"Accept": "application/json", "Date": "Mon"})
+
+	if h := hash(newRequest("https://api.example.com/users?a=1&b=2", `{"id":1}`, map[string]string{"accept": "application/json", "Date": "Tue", "X-Request-Id": "7"})); hash(base) != h {
+		t.Errorf("Equivalent requests should have the same hash.")
+	}
+
+	if h := hash(newRequest("https://api.example.com/users?a=1&b=2", `{"id":2}`, map[string]string{"Accept": "application/json"})); hash(base) == h {
+		t.Errorf("Requests with different bodies should have different hashes.")
+	}
+
+	if bs, _ := ioutil.ReadAll(base.Body); `{"id":1}` != string(bs) {
+		t.Errorf("Unexpected request body after hashing (expected: %v, actual: %s).", `{"id":1}`, bs)
+	}
+}
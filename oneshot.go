@@ -0,0 +1,187 @@
+package currly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultOneShotTimeout = 30 * time.Second
+
+var (
+	sharedConnector Connector
+	oneShotOnce     sync.Once
+)
+
+func GetJSON(ctx context.Context, url string, out interface{}, opts ...OneShotOption) error {
+	return oneShot(ctx, http.MethodGet, url, nil, out, opts)
+}
+
+func PostJSON(ctx context.Context, url string, body, out interface{}, opts ...OneShotOption) error {
+	return oneShot(ctx, http.MethodPost, url, JSONBodyArg(body), out, opts)
+}
+
+func OneShotConnector(con Connector) OneShotOption {
+	return func(o *oneShotOptions) {
+		o.connector = con
+	}
+}
+
+func OneShotTimeout(d time.Duration) OneShotOption {
+	return func(o *oneShotOptions) {
+		o.timeout = d
+	}
+}
+
+func OneShotArgs(args ...Arg) OneShotOption {
+	return func(o *oneShotOptions) {
+		o.args = append(o.args, args...)
+	}
+}
+
+func OneShotTemplate(configure func(t SetResultExtractor) SetResultExtractor) OneShotOption {
+	return func(o *oneShotOptions) {
+		o.configure = append(o.configure, configure)
+	}
+}
+
+func oneShot(ctx context.Context, method, rawURL string, body Arg, out interface{}, opts []OneShotOption) error {
+	o := oneShotOptions{timeout: defaultOneShotTimeout}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.connector == nil {
+		oneShotOnce.Do(func() { sharedConnector = ConfiguredConnector(ConnectorOptions{}) })
+
+		o.connector = sharedConnector
+	}
+
+	t, err := templateFromURL(method, rawURL)
+
+	if err != nil {
+		return err
+	}
+
+	if o.timeout > 0 {
+		t = t.Timeout(o.timeout)
+	}
+
+	for _, configure := range o.configure {
+		t = configure(t)
+	}
+
+	curl, err := t.AcceptStatus(successStatusCodes()...).ResultExtractor(decodingExtractor(out)).Build()
+
+	if err != nil {
+		return err
+	}
+
+	args := append([]Arg{ContextArg(ctx)}, o.args...)
+
+	if body != nil {
+		args = append(args, body)
+	}
+
+	_, _, err = curl(o.connector, args...)
+
+	return err
+}
+
+func templateFromURL(method, rawURL string) (SetResultExtractor, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("currly: URL '%v' is invalid: %w", rawURL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("currly: URL '%v' must use the http or https scheme", rawURL)
+	}
+
+	if len(u.Hostname()) == 0 {
+		return nil, fmt.Errorf("currly: URL '%v' has no host", rawURL)
+	}
+
+	dp := Builder().Method(method).Scheme(u.Scheme).Host(u.Hostname())
+
+	var b BuildPath = dp
+
+	if len(u.Port()) > 0 {
+		port, err := strconv.ParseUint(u.Port(), 10, 16)
+
+		if err != nil {
+			return nil, fmt.Errorf("currly: URL '%v' has an invalid port", rawURL)
+		}
+
+		b = dp.Port(uint(port))
+	}
+
+	for _, s := range strings.Split(u.EscapedPath(), "/") {
+		if len(s) > 0 {
+			b = b.PathSegment(s)
+		}
+	}
+
+	var sc SetCredentials = b
+
+	if len(u.RawQuery) > 0 {
+		sc = b.RawQuery(u.RawQuery)
+	}
+
+	if u.User != nil {
+		password, _ := u.User.Password()
+
+		return sc.Credentials(u.User.Username(), password), nil
+	}
+
+	return sc, nil
+}
+
+func decodingExtractor(out interface{}) ResultExtractor {
+	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		if out == nil {
+			return nil, nil
+		}
+
+		err := json.NewDecoder(r.Body).Decode(out)
+
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("currly: decoding the JSON response failed: %w", err)
+		}
+
+		return out, nil
+	})
+}
+
+func successStatusCodes() []int {
+	codes := make([]int, 0, 100)
+
+	for sc := 200; sc < 300; sc++ {
+		codes = append(codes, sc)
+	}
+
+	return codes
+}
+
+type OneShotOption func(o *oneShotOptions)
+
+type oneShotOptions struct {
+	connector Connector
+	timeout   time.Duration
+	args      []Arg
+	configure []func(t SetResultExtractor) SetResultExtractor
+}
@@ -0,0 +1,115 @@
+package currly_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestGetJSONDecodesResponses(t *testing.T) {
+	var path, query, tenant string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query, tenant = r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Tenant")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 42, "name": "Bob"}`))
+	}))
+	defer srv.Close()
+
+	var user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	err := currly.GetJSON(context.Background(), srv.URL+"/users/42?expand=true", &user,
+		currly.OneShotTemplate(func(t currly.SetResultExtractor) currly.SetResultExtractor {
+			return t.Use(currly.DefaultHeaderMiddleware(http.Header{"X-Tenant": {"acme"}}))
+		}))
+
+	if err != nil {
+		t.Fatalf("Getting the JSON document returned an unexpected error: %v", err)
+	}
+
+	if 42 != user.ID || "Bob" != user.Name {
+		t.Errorf("Unexpected decoded value: %+v.", user)
+	}
+
+	if "/users/42" != path || "expand=true" != query || "acme" != tenant {
+		t.Errorf("Unexpected request (path: %v, query: %v, tenant: %v).", path, query, tenant)
+	}
+}
+
+func TestPostJSONReturnsTypedErrors(t *testing.T) {
+	var body string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		body = string(bs)
+
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error": "invalid"}`))
+	}))
+	defer srv.Close()
+
+	var out map[string]interface{}
+
+	err := currly.PostJSON(context.Background(), srv.URL+"/users", map[string]string{"name": "Bob"}, &out)
+
+	var re *currly.ResponseError
+
+	if !errors.As(err, &re) || http.StatusUnprocessableEntity != re.StatusCode || !errors.Is(err, currly.ErrUnexpectedStatus) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrUnexpectedStatus, err)
+	}
+
+	if `{"name":"Bob"}` != body {
+		t.Errorf("Unexpected request body (expected: %v, actual: %v).", `{"name":"Bob"}`, body)
+	}
+
+	err = currly.PostJSON(context.Background(), srv.URL+"/slow", nil, &out, currly.OneShotTimeout(20*time.Millisecond))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+
+	for _, u := range []string{"ftp://example.com", "https://", "://"} {
+		if err := currly.GetJSON(context.Background(), u, &out); err == nil {
+			t.Errorf("Getting '%v' should fail.", u)
+		}
+	}
+}
+
+func TestGetJSONSendsURLCredentials(t *testing.T) {
+	var username, password string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	u.User = url.UserPassword("bob", "secret")
+
+	var out map[string]interface{}
+
+	if err := currly.GetJSON(context.Background(), u.String()+"/users", &out); err != nil {
+		t.Fatalf("Getting the JSON document returned an unexpected error: %v", err)
+	}
+
+	if "bob" != username || "secret" != password {
+		t.Errorf("Unexpected credentials (username: %v, password: %v).", username, password)
+	}
+}
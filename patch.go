@@ -0,0 +1,63 @@
+package currly
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func JSONPatchBodyArg(ops []PatchOp) Arg {
+	for _, op := range ops {
+		if err := op.validate(); err != nil {
+			return argFunc(func(ct *curlTemplate) error { return err })
+		}
+	}
+
+	return jsonBodyArg(append([]PatchOp{}, ops...), "application/json-patch+json")
+}
+
+func MergePatchBodyArg(v interface{}) Arg {
+	return jsonBodyArg(v, "application/merge-patch+json")
+}
+
+type PatchOp struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{"op": op.Op, "path": op.Path}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		m["value"] = op.Value
+	case "move", "copy":
+		m["from"] = op.From
+	}
+
+	return json.Marshal(m)
+}
+
+func (op PatchOp) validate() error {
+	switch op.Op {
+	case "add", "remove", "replace", "test":
+	case "move", "copy":
+		if !isJSONPointer(op.From) {
+			return fmt.Errorf("currly: invalid JSON patch 'from' pointer '%v'", op.From)
+		}
+	default:
+		return fmt.Errorf("currly: invalid JSON patch operation '%v'", op.Op)
+	}
+
+	if !isJSONPointer(op.Path) {
+		return fmt.Errorf("currly: invalid JSON patch path pointer '%v'", op.Path)
+	}
+
+	return nil
+}
+
+func isJSONPointer(p string) bool {
+	return len(p) == 0 || strings.HasPrefix(p, "/")
+}
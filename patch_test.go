@@ -0,0 +1,82 @@
+package currly_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestPatchBodyArgsSetMediaTypes(t *testing.T) {
+	var method, contentType, body string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		method, contentType, body = r.Method, r.Header.Get("Content-Type"), string(bs)
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().PATCH().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		arg         currly.Arg
+		contentType string
+		body        string
+	}{
+		{
+			currly.JSONPatchBodyArg([]currly.PatchOp{
+				{Op: "replace", Path: "/name", Value: "currly"},
+				{Op: "add", Path: "/tags/-", Value: nil},
+				{Op: "move", Path: "/b", From: "/a"},
+				{Op: "remove", Path: "/c", Value: "ignored"},
+			}),
+			"application/json-patch+json",
+			`[{"op":"replace","path":"/name","value":"currly"},{"op":"add","path":"/tags/-","value":null},{"from":"/a","op":"move","path":"/b"},{"op":"remove","path":"/c"}]`,
+		},
+		{
+			currly.MergePatchBodyArg(map[string]interface{}{"name": "currly", "tags": nil}),
+			"application/merge-patch+json",
+			`{"name":"currly","tags":null}`,
+		},
+	}
+
+	for _, c := range cases {
+		if _, _, err = curl(con, c.arg); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if http.MethodPatch != method {
+			t.Errorf("Unexpected method (expected: %v, actual: %v).", http.MethodPatch, method)
+		}
+
+		if c.contentType != contentType {
+			t.Errorf("Unexpected content type (expected: %v, actual: %v).", c.contentType, contentType)
+		}
+
+		if c.body != body {
+			t.Errorf("Unexpected body (expected: %v, actual: %v).", c.body, body)
+		}
+	}
+}
+
+func TestJSONPatchBodyArgRejectsInvalidOperations(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().PATCH().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	for _, op := range []currly.PatchOp{{Op: "merge", Path: "/a"}, {Op: "add", Path: "a"}, {Op: "copy", Path: "/a", From: "b"}} {
+		if _, _, err = curl(con, currly.JSONPatchBodyArg([]currly.PatchOp{op})); err == nil {
+			t.Errorf("Expected an error for patch operation %+v.", op)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package currly
+
+import (
+	"errors"
+	"net/http"
+)
+
+func PatternOf(b BuildCurl) (Pattern, error) {
+	ct, ok := b.(curlTemplate)
+
+	if !ok {
+		return Pattern{}, errors.New("currly: template was not created by currly")
+	}
+
+	if ct.error != nil {
+		return Pattern{}, ct.error
+	}
+
+	return Pattern{Method: ct.method, URL: urlPattern(ct.urlTemplate), Header: copyHeader(ct.header)}, nil
+}
+
+type Pattern struct {
+	Method string
+	URL    string
+	Header http.Header
+}
@@ -0,0 +1,93 @@
+package currly
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+var ErrUnexpectedStatus = errors.New("currly: unexpected HTTP status")
+
+func GzipProcessor() ResponseProcessor {
+	return func(r *http.Response) (*http.Response, error) {
+		if !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+			return r, nil
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rCopy := *r
+		rCopy.Header = copyHeader(r.Header)
+		rCopy.Header.Del("Content-Encoding")
+		rCopy.Header.Del("Content-Length")
+		rCopy.ContentLength = -1
+		rCopy.Uncompressed = true
+		rCopy.Body = zr
+
+		return &rCopy, nil
+	}
+}
+
+type ResponseProcessor func(r *http.Response) (*http.Response, error)
+
+type ResultMapper func(v interface{}) (interface{}, error)
+
+func extract(ct curlTemplate, r *http.Response, er **excerptReader) (interface{}, error) {
+	var err error
+
+	for _, p := range ct.processors {
+		if r, err = p(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Body != io.ReadCloser(*er) {
+		*er = newExcerptReader(r.Body, ct.errorBodyLimit)
+		r.Body = *er
+	}
+
+	if !ct.accepts(r.StatusCode) {
+		bs, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return string(bs), ErrUnexpectedStatus
+	}
+
+	v, err := ct.resultExtractor.Result(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range ct.mappers {
+		if v, err = m(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+func (ct curlTemplate) accepts(statusCode int) bool {
+	if len(ct.acceptStatus) == 0 {
+		return true
+	}
+
+	for _, sc := range ct.acceptStatus {
+		if sc == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package currly_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestResponsePipelineRunsInDeclarationOrder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+
+	zw.Write([]byte("hello, currly"))
+	zw.Close()
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, "")
+		resp.Header.Set("Content-Encoding", "gzip")
+		resp.Body = ioutil.NopCloser(bytes.NewReader(buf.Bytes()))
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		Process(currly.GzipProcessor()).
+		Map(func(v interface{}) (interface{}, error) { return strings.ToUpper(v.(string)), nil }).
+		Map(func(v interface{}) (interface{}, error) { return v.(string) + "!", nil }).
+		ResultExtractor(currly.PlainStringExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, res, err := curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "HELLO, CURRLY!" != res {
+		t.Errorf("Unexpected result (expected: %v, actual: %v).", "HELLO, CURRLY!", res)
+	}
+}
+
+func TestExtractorArgOverridesTemplateExtractor(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, "hello, currly"), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		Map(func(v interface{}) (interface{}, error) { return strings.ToUpper(v.(string)), nil }).
+		ResultExtractor(currly.PlainStringExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, res, err := curl(con, currly.ExtractorArg(currly.BytesExtractor()))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if bs, ok := res.([]byte); !ok || "hello, currly" != string(bs) {
+		t.Errorf("Unexpected result (expected: %v, actual: %v).", "hello, currly", res)
+	}
+
+	_, res, err = curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "HELLO, CURRLY" != res {
+		t.Errorf("Unexpected result (expected: %v, actual: %v).", "HELLO, CURRLY", res)
+	}
+
+	if _, _, err = curl(con, currly.ExtractorArg(nil)); err == nil {
+		t.Errorf("Expected an error for a nil result extractor.")
+	}
+}
@@ -0,0 +1,172 @@
+package currly
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+func NewPolicyRegistry() *PolicyRegistry {
+	return &PolicyRegistry{hosts: make(map[string]*hostPolicy)}
+}
+
+type PolicyRegistry struct {
+	mutex   sync.RWMutex
+	hosts   map[string]*hostPolicy
+	version uint64
+}
+
+type HostPolicy struct {
+	Timeout               time.Duration
+	ResponseHeaderTimeout time.Duration
+	Retry                 *RetryPolicy
+	RateLimit             *RateLimit
+	TLSServerName         string
+	Clock                 Clock
+}
+
+type hostPolicy struct {
+	HostPolicy
+	mws []Middleware
+}
+
+func (pr *PolicyRegistry) Set(host string, p HostPolicy) {
+	hp := &hostPolicy{HostPolicy: p}
+
+	if p.Retry != nil {
+		hp.mws = append(hp.mws, RetryMiddleware(*p.Retry))
+	}
+
+	if p.RateLimit != nil {
+		hp.mws = append(hp.mws, RateLimitMiddleware(*p.RateLimit))
+	}
+
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	pr.hosts[strings.ToLower(host)] = hp
+	pr.version++
+}
+
+func (pr *PolicyRegistry) Remove(host string) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	delete(pr.hosts, strings.ToLower(host))
+	pr.version++
+}
+
+func (pr *PolicyRegistry) Policy(host string) (HostPolicy, bool) {
+	_, hp, _ := pr.lookup(&url.URL{Host: host})
+
+	if hp == nil {
+		return HostPolicy{}, false
+	}
+
+	return hp.HostPolicy, true
+}
+
+func (pr *PolicyRegistry) Middleware() Middleware {
+	return Named("host-policy", func(next Connector) Connector {
+		var (
+			mutex   sync.Mutex
+			version uint64
+		)
+
+		connectors := make(map[string]Connector)
+
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			host, hp, v := pr.lookup(r.URL)
+
+			if hp == nil {
+				return next.Send(r)
+			}
+
+			mutex.Lock()
+
+			if v != version {
+				connectors = make(map[string]Connector)
+				version = v
+			}
+
+			con, ok := connectors[host]
+
+			if !ok {
+				con = next
+
+				for i := len(hp.mws) - 1; i >= 0; i-- {
+					con = hp.mws[i](con)
+				}
+
+				connectors[host] = con
+			}
+
+			mutex.Unlock()
+
+			return hp.send(con, r)
+		})
+	})
+}
+
+func (pr *PolicyRegistry) lookup(u *url.URL) (string, *hostPolicy, uint64) {
+	pr.mutex.RLock()
+	defer pr.mutex.RUnlock()
+
+	host := strings.ToLower(u.Host)
+
+	if hp, ok := pr.hosts[host]; ok {
+		return host, hp, pr.version
+	}
+
+	host = strings.ToLower(u.Hostname())
+
+	return host, pr.hosts[host], pr.version
+}
+
+func (hp *hostPolicy) send(con Connector, r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	cancel := context.CancelFunc(func() {})
+
+	if _, ok := TLSServerNameFromContext(ctx); !ok && len(hp.TLSServerName) > 0 {
+		ctx = context.WithValue(ctx, tlsServerNameKey{}, hp.TLSServerName)
+	}
+
+	if hp.Timeout > 0 {
+		ctx, cancel = withTimeout(ctx, hp.Clock, hp.Timeout)
+	}
+
+	var phases *phaseTimeouts
+
+	if hp.ResponseHeaderTimeout > 0 {
+		phases = newPhaseTimeouts(ctx, hp.Clock)
+		ctx = phases.ctx
+	}
+
+	done := func() {
+		phases.close()
+		cancel()
+	}
+	stopHeaderTimeout := phases.start(hp.ResponseHeaderTimeout, ErrResponseHeaderTimeout)
+	resp, err := con.Send(r.WithContext(ctx))
+
+	stopHeaderTimeout()
+
+	if err != nil {
+		done()
+
+		return nil, err
+	}
+
+	if resp.Body == nil {
+		done()
+
+		return resp, nil
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: done}
+
+	return resp, nil
+}
@@ -0,0 +1,164 @@
+package currly_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestPolicyRegistryAppliesHostPolicies(t *testing.T) {
+	calls := make(map[string]int)
+	serverNames := make(map[string]string)
+	policies := currly.NewPolicyRegistry()
+
+	policies.Set("api.example.com", currly.HostPolicy{
+		Retry:         &currly.RetryPolicy{MaxAttempts: 3},
+		TLSServerName: "internal.example.com",
+	})
+
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls[r.URL.Host]++
+		serverNames[r.URL.Host], _ = currly.TLSServerNameFromContext(r.Context())
+		resp := okResponse(r, "")
+		resp.StatusCode = http.StatusServiceUnavailable
+
+		return resp, nil
+	}), policies.Middleware())
+
+	for _, host := range []string{"api.example.com", "other.example.com"} {
+		curl, err := currly.Builder().GET().HTTPS().Host(host).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		if _, _, err = curl(con); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+	}
+
+	if 3 != calls["api.example.com"] || 1 != calls["other.example.com"] {
+		t.Errorf("Unexpected number of attempts per host: %v.", calls)
+	}
+
+	if "internal.example.com" != serverNames["api.example.com"] || "" != serverNames["other.example.com"] {
+		t.Errorf("Unexpected TLS server names per host: %v.", serverNames)
+	}
+
+	if _, ok := policies.Policy("API.example.com:443"); !ok {
+		t.Errorf("Expected a policy for host %v.", "API.example.com:443")
+	}
+
+	policies.Remove("api.example.com")
+
+	if _, ok := policies.Policy("api.example.com"); ok {
+		t.Errorf("Unexpected policy for removed host %v.", "api.example.com")
+	}
+}
+
+func TestPolicyRegistrySharesRateLimitsAndTimeouts(t *testing.T) {
+	clock := currlytest.NewFakeClock(time.Now())
+	policies := currly.NewPolicyRegistry()
+
+	policies.Set("localhost", currly.HostPolicy{
+		Timeout:   time.Minute,
+		RateLimit: &currly.RateLimit{PerSecond: 1, Burst: 1, Clock: clock},
+		Clock:     clock,
+	})
+
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+
+			return nil, r.Context().Err()
+		}
+
+		return okResponse(r, ""), nil
+	}), policies.Middleware())
+	fast, err := currly.Builder().GET().HTTPS().Localhost().PathSegment("fast").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	slow, err := currly.Builder().GET().HTTPS().Localhost().PathSegment("slow").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = fast(con); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, _, err := slow(con)
+		done <- err
+	}()
+
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+}
+
+func TestDefaultConnectorAppliesRegisteredPolicies(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().GET().HTTP().Host(u.Hostname()).Port(uint(port)).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	policies := currly.NewPolicyRegistry()
+	con := currly.DefaultConnector(currly.WithPolicies(policies))
+	cases := []struct {
+		retry    *currly.RetryPolicy
+		remove   bool
+		expected int
+	}{
+		{&currly.RetryPolicy{MaxAttempts: 3}, false, 3},
+		{&currly.RetryPolicy{MaxAttempts: 2}, false, 2},
+		{nil, true, 1},
+	}
+
+	for i, c := range cases {
+		if c.remove {
+			policies.Remove(u.Host)
+		} else {
+			policies.Set(u.Host, currly.HostPolicy{Retry: c.retry})
+		}
+
+		calls = 0
+
+		if _, _, err := curl(con); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if c.expected != calls {
+			t.Errorf("Unexpected number of attempts in case %v (expected: %v, actual: %v).", i, c.expected, calls)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package currly
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func RateLimitMiddleware(l RateLimit) Middleware {
+	if l.PerSecond <= 0 || l.Burst <= 0 {
+		return failingMiddleware("rate-limit", fmt.Errorf("currly: invalid rate limit %v/s (burst %v)", l.PerSecond, l.Burst))
+	}
+
+	clock := clockOrSystem(l.Clock)
+	b := &tokenBucket{rate: l.PerSecond, burst: float64(l.Burst), tokens: float64(l.Burst), last: clock.Now()}
+
+	return Named("rate-limit", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			if !sleep(r, clock, b.reserve(clock.Now())) {
+				b.refund()
+
+				return nil, r.Context().Err()
+			}
+
+			return next.Send(r)
+		})
+	})
+}
+
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+	Clock     Clock
+}
+
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		b.last = now
+	}
+
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) refund() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.tokens++
+
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
@@ -0,0 +1,102 @@
+package currly_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestRateLimitMiddlewareDelaysRequestsBeyondBurst(t *testing.T) {
+	var calls int32
+
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+
+		return okResponse(r, ""), nil
+	}), currly.RateLimitMiddleware(currly.RateLimit{PerSecond: 2, Burst: 2, Clock: clock}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con)
+	curl(con)
+
+	done := make(chan struct{})
+
+	go func() {
+		curl(con)
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+
+	if 2 != atomic.LoadInt32(&calls) {
+		t.Errorf("Unexpected number of calls within the burst (expected: %v, actual: %v).", 2, calls)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	<-done
+
+	if 3 != atomic.LoadInt32(&calls) {
+		t.Errorf("Unexpected number of calls after waiting (expected: %v, actual: %v).", 3, calls)
+	}
+}
+
+func TestRateLimitMiddlewareRefundsCancelledReservations(t *testing.T) {
+	var calls int32
+
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+
+		return okResponse(r, ""), nil
+	}), currly.RateLimitMiddleware(currly.RateLimit{PerSecond: 1, Burst: 1, Clock: clock}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		_, _, err := curl(con, currly.ContextArg(ctx))
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+
+	if err := <-done; err == nil {
+		t.Fatalf("A cancelled reservation should fail the call.")
+	}
+
+	clock.Advance(time.Second)
+	curl(con)
+
+	if 2 != atomic.LoadInt32(&calls) {
+		t.Errorf("Unexpected number of calls (expected: %v, actual: %v).", 2, calls)
+	}
+}
+
+func TestRateLimitMiddlewareRejectsInvalidLimits(t *testing.T) {
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	}), currly.RateLimitMiddleware(currly.RateLimit{PerSecond: 0, Burst: 1}))
+	curl, _ := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if _, _, err := curl(con); err == nil {
+		t.Errorf("A rate limit of 0/s should be rejected.")
+	}
+}
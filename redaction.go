@@ -0,0 +1,131 @@
+package currly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+var DefaultRedactionPolicy = RedactionPolicy{
+	Headers:     []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+	QueryParams: []string{"access_token", "api_key", "apikey", "token", "password"},
+	JSONFields:  []string{"password", "secret", "token", "access_token", "refresh_token"},
+}
+
+func RedactionPolicyFromContext(ctx context.Context) RedactionPolicy {
+	if p := callInfoFrom(ctx).redaction; p != nil {
+		return *p
+	}
+
+	return DefaultRedactionPolicy
+}
+
+type RedactionPolicy struct {
+	Headers     []string
+	QueryParams []string
+	JSONFields  []string
+}
+
+func (p RedactionPolicy) Header(h http.Header) http.Header {
+	hCopy := copyHeader(h)
+
+	for k, v := range hCopy {
+		if contains(p.Headers, k) {
+			for i := range v {
+				v[i] = redacted
+			}
+		}
+	}
+
+	return hCopy
+}
+
+func (p RedactionPolicy) URL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	uCopy := *u
+	uCopy.User = nil
+
+	if len(uCopy.RawQuery) == 0 {
+		return uCopy.String()
+	}
+
+	pairs := strings.Split(uCopy.RawQuery, "&")
+
+	for i, pair := range pairs {
+		name := pair
+
+		if j := strings.Index(pair, "="); j >= 0 {
+			name = pair[:j]
+		}
+
+		if n, err := url.QueryUnescape(name); err == nil && contains(p.QueryParams, n) {
+			pairs[i] = name + "=" + redacted
+		}
+	}
+
+	uCopy.RawQuery = strings.Join(pairs, "&")
+
+	return uCopy.String()
+}
+
+func (p RedactionPolicy) JSON(bs []byte) []byte {
+	var v interface{}
+
+	if err := json.Unmarshal(bs, &v); err != nil {
+		return p.redactJSONText(bs)
+	}
+
+	rbs, err := json.Marshal(p.redactJSONValue(v))
+
+	if err != nil {
+		return bs
+	}
+
+	return rbs
+}
+
+func (p RedactionPolicy) redactJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range v {
+			if contains(p.JSONFields, k) {
+				v[k] = redacted
+			} else {
+				v[k] = p.redactJSONValue(fv)
+			}
+		}
+	case []interface{}:
+		for i, ev := range v {
+			v[i] = p.redactJSONValue(ev)
+		}
+	}
+
+	return v
+}
+
+func (p RedactionPolicy) redactJSONText(bs []byte) []byte {
+	for _, f := range p.JSONFields {
+		re := regexp.MustCompile(`(?i)("` + regexp.QuoteMeta(f) + `"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]*)`)
+		bs = re.ReplaceAll(bs, []byte(`${1}"`+redacted+`"`))
+	}
+
+	return bs
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package currly_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestRedactionPolicyMasksSecrets(t *testing.T) {
+	p := currly.RedactionPolicy{
+		Headers:     []string{"Authorization"},
+		QueryParams: []string{"api_key"},
+		JSONFields:  []string{"password"},
+	}
+	h := p.Header(http.Header{"Authorization": {"Bearer s3cr3t"}, "Accept": {"*/*"}})
+
+	if "REDACTED" != h.Get("Authorization") {
+		t.Errorf("Unexpected Authorization header (expected: %v, actual: %v).", "REDACTED", h.Get("Authorization"))
+	}
+
+	if "*/*" != h.Get("Accept") {
+		t.Errorf("Unexpected Accept header (expected: %v, actual: %v).", "*/*", h.Get("Accept"))
+	}
+
+	u, _ := url.Parse("https://localhost/users?api_key=s3cr3t&page=2")
+	expected := "https://localhost/users?api_key=REDACTED&page=2"
+
+	if actual := p.URL(u); expected != actual {
+		t.Errorf("Unexpected URL (expected: %v, actual: %v).", expected, actual)
+	}
+
+	expected = `{"user":{"name":"bob","password":"REDACTED"}}`
+
+	if actual := string(p.JSON([]byte(`{"user":{"name":"bob","password":"s3cr3t"}}`))); expected != actual {
+		t.Errorf("Unexpected JSON (expected: %v, actual: %v).", expected, actual)
+	}
+}
+
+func TestRedactionPolicyMasksSecretsInTruncatedJSON(t *testing.T) {
+	p := currly.RedactionPolicy{JSONFields: []string{"token", "pin"}}
+	expected := `{"Token": "REDACTED", "pin":"REDACTED", "name": "bo`
+
+	if actual := string(p.JSON([]byte(`{"Token": "abc\"def", "pin":1234, "name": "bo`))); expected != actual {
+		t.Errorf("Unexpected JSON (expected: %v, actual: %v).", expected, actual)
+	}
+}
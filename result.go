@@ -0,0 +1,76 @@
+package currly
+
+import (
+	"net/http"
+	"net/url"
+)
+
+func ResultArg(r *Result) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		ct.result = r
+
+		return nil
+	})
+}
+
+type Result struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Cookies    []*http.Cookie
+	URL        *url.URL
+	Metadata   ResponseMetadata
+	Labels     map[string]string
+	Value      interface{}
+}
+
+func (r Result) IsInformational() bool {
+	return r.StatusCode >= 100 && r.StatusCode < 200
+}
+
+func (r Result) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+func (r Result) IsRedirect() bool {
+	return r.StatusCode >= 300 && r.StatusCode < 400
+}
+
+func (r Result) IsClientError() bool {
+	return r.StatusCode >= 400 && r.StatusCode < 500
+}
+
+func (r Result) IsServerError() bool {
+	return r.StatusCode >= 500 && r.StatusCode < 600
+}
+
+func (r Result) IsError() bool {
+	return r.IsClientError() || r.IsServerError()
+}
+
+func recordResponse(ct curlTemplate, resp *http.Response) {
+	if ct.result == nil {
+		return
+	}
+
+	*ct.result = Result{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		Metadata:   ParseMetadata(resp.Header, clockOrSystem(ct.clock).Now()),
+		Labels:     copyLabels(ct.labels),
+	}
+
+	if resp.Request != nil {
+		ct.result.URL = resp.Request.URL
+	}
+
+	ct.result.Metadata.ServedBy = ServedBy(resp)
+}
+
+func recordValue(ct curlTemplate, v interface{}) {
+	if ct.result != nil {
+		ct.result.Value = v
+	}
+}
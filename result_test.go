@@ -0,0 +1,60 @@
+package currly_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestResultArgCapturesRichResult(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, "created")
+		resp.StatusCode = http.StatusCreated
+		resp.Header.Set("Location", "/users/42")
+
+		return resp, nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().ResultExtractor(currly.PlainStringExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var res currly.Result
+
+	_, _, err = curl(con, currly.ResultArg(&res))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if !res.IsSuccess() || res.IsRedirect() || res.IsClientError() || res.IsServerError() {
+		t.Errorf("Unexpected status class for status code %v.", res.StatusCode)
+	}
+
+	if "/users/42" != res.Header.Get("Location") {
+		t.Errorf("Unexpected Location header (expected: %v, actual: %v).", "/users/42", res.Header.Get("Location"))
+	}
+
+	if "created" != res.Value {
+		t.Errorf("Unexpected result value (expected: %v, actual: %v).", "created", res.Value)
+	}
+}
+
+func TestResultStatusClasses(t *testing.T) {
+	cases := map[int]func(r currly.Result) bool{
+		http.StatusContinue:            currly.Result.IsInformational,
+		http.StatusNoContent:           currly.Result.IsSuccess,
+		http.StatusFound:               currly.Result.IsRedirect,
+		http.StatusNotFound:            currly.Result.IsClientError,
+		http.StatusServiceUnavailable:  currly.Result.IsServerError,
+		http.StatusInternalServerError: currly.Result.IsError,
+	}
+
+	for sc, is := range cases {
+		if !is(currly.Result{StatusCode: sc}) {
+			t.Errorf("Unexpected status class for status code %v.", sc)
+		}
+	}
+}
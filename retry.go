@@ -0,0 +1,195 @@
+package currly
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	methodIdempotency idempotency = iota
+	idempotent
+	nonIdempotent
+)
+
+func IdempotentArg(isIdempotent bool) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		if isIdempotent {
+			ct.idempotency = idempotent
+		} else {
+			ct.idempotency = nonIdempotent
+		}
+
+		return nil
+	})
+}
+
+func RetryMiddleware(p RetryPolicy) Middleware {
+	if p.RetryOn == nil {
+		p.RetryOn = retryOnTransientFailure
+	}
+
+	clock := clockOrSystem(p.Clock)
+
+	return Named("retry", func(next Connector) Connector {
+		return ConnectorFunc(func(r *http.Request) (*http.Response, error) {
+			if p.Budget != nil {
+				p.Budget.recordRequest(clock.Now())
+			}
+
+			ci := callInfoFrom(r.Context())
+			resp, err := next.Send(r)
+
+			for attempt := 2; attempt <= p.MaxAttempts && p.RetryOn(resp, err); attempt++ {
+				if !replayable(r) || !ci.idempotent(r) || (p.Budget != nil && !p.Budget.allowRetry(clock.Now())) {
+					break
+				}
+
+				if err == nil {
+					DrainBody(resp.Body)
+				}
+
+				if !sleep(r, clock, p.backoff(attempt)) {
+					return nil, r.Context().Err()
+				}
+
+				ci.attempt = attempt
+				rCopy := r.Clone(withCallInfo(r.Context(), ci))
+
+				if r.GetBody != nil {
+					if rCopy.Body, err = r.GetBody(); err != nil {
+						return nil, err
+					}
+				}
+
+				resp, err = next.Send(rCopy)
+			}
+
+			return resp, err
+		})
+	})
+}
+
+func NewRetryBudget(ratio float64, minRetriesPerMinute int) *RetryBudget {
+	return &RetryBudget{ratio: ratio, minRetries: int64(minRetriesPerMinute)}
+}
+
+type idempotency int
+
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	RetryOn     func(resp *http.Response, err error) bool
+	Budget      *RetryBudget
+	Clock       Clock
+}
+
+type RetryBudget struct {
+	mutex       sync.Mutex
+	ratio       float64
+	minRetries  int64
+	windowStart time.Time
+	requests    int64
+	retries     int64
+	stats       RetryBudgetStats
+}
+
+type RetryBudgetStats struct {
+	Requests  int64
+	Retries   int64
+	Exhausted int64
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	return p.Backoff << uint(attempt-2)
+}
+
+func (b *RetryBudget) Stats() RetryBudgetStats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.stats
+}
+
+func (b *RetryBudget) recordRequest(now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.roll(now)
+	b.requests++
+	b.stats.Requests++
+}
+
+func (b *RetryBudget) allowRetry(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.roll(now)
+
+	if b.retries >= b.minRetries && float64(b.retries+1) > b.ratio*float64(b.requests) {
+		b.stats.Exhausted++
+
+		return false
+	}
+
+	b.retries++
+	b.stats.Retries++
+
+	return true
+}
+
+func (b *RetryBudget) roll(now time.Time) {
+	if now.Sub(b.windowStart) < time.Minute {
+		return
+	}
+
+	b.windowStart = now
+	b.requests = 0
+	b.retries = 0
+}
+
+func retryOnTransientFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (ci callInfo) idempotent(r *http.Request) bool {
+	switch ci.idempotency {
+	case idempotent:
+		return true
+	case nonIdempotent:
+		return false
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func replayable(r *http.Request) bool {
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+func sleep(r *http.Request, c Clock, d time.Duration) bool {
+	if d <= 0 {
+		return r.Context().Err() == nil
+	}
+
+	select {
+	case <-c.After(d):
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
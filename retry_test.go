@@ -0,0 +1,163 @@
+package currly_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestRetryMiddlewareRetriesTransientFailures(t *testing.T) {
+	var calls int
+
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		resp := okResponse(r, "")
+
+		if calls < 3 {
+			resp.StatusCode = http.StatusServiceUnavailable
+		}
+
+		return resp, nil
+	}), currly.RetryMiddleware(currly.RetryPolicy{MaxAttempts: 3}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	sc, _, err := curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if http.StatusOK != sc {
+		t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusOK, sc)
+	}
+
+	if 3 != calls {
+		t.Errorf("Unexpected number of attempts (expected: %v, actual: %v).", 3, calls)
+	}
+}
+
+func TestRetryBudgetLimitsRetries(t *testing.T) {
+	var calls int
+
+	budget := currly.NewRetryBudget(0, 1)
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		resp := okResponse(r, "")
+		resp.StatusCode = http.StatusServiceUnavailable
+
+		return resp, nil
+	}), currly.RetryMiddleware(currly.RetryPolicy{MaxAttempts: 5, Budget: budget}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.BytesExtractor()).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con)
+
+	if 2 != calls {
+		t.Errorf("Unexpected number of attempts (expected: %v, actual: %v).", 2, calls)
+	}
+
+	stats := budget.Stats()
+
+	if 1 != stats.Retries || 1 != stats.Exhausted {
+		t.Errorf("Unexpected budget statistics (expected: 1 retry, 1 exhaustion, actual: %+v).", stats)
+	}
+}
+
+func TestRetryMiddlewareWaitsForBackoffOnClock(t *testing.T) {
+	var calls int32
+
+	clock := currlytest.NewFakeClock(time.Unix(0, 0))
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, "")
+
+		if atomic.AddInt32(&calls, 1) < 3 {
+			resp.StatusCode = http.StatusServiceUnavailable
+		}
+
+		return resp, nil
+	}), currly.RetryMiddleware(currly.RetryPolicy{MaxAttempts: 3, Backoff: time.Hour, Clock: clock}))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	done := make(chan int, 1)
+
+	go func() {
+		sc, _, _ := curl(con)
+		done <- sc
+	}()
+
+	clock.BlockUntil(1)
+
+	if 1 != atomic.LoadInt32(&calls) {
+		t.Errorf("Unexpected number of attempts before the backoff (expected: %v, actual: %v).", 1, calls)
+	}
+
+	clock.Advance(time.Hour)
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Hour)
+
+	if sc := <-done; http.StatusOK != sc {
+		t.Errorf("Unexpected HTTP status code (expected: %v, actual: %v).", http.StatusOK, sc)
+	}
+
+	if 3 != atomic.LoadInt32(&calls) {
+		t.Errorf("Unexpected number of attempts (expected: %v, actual: %v).", 3, calls)
+	}
+}
+
+func TestRetryMiddlewareOnlyReplaysIdempotentCalls(t *testing.T) {
+	var calls int
+
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		resp := okResponse(r, "")
+		resp.StatusCode = http.StatusServiceUnavailable
+
+		return resp, nil
+	}), currly.RetryMiddleware(currly.RetryPolicy{MaxAttempts: 3}))
+	post := currly.Builder().POST().HTTPS().Localhost()
+	cases := []struct {
+		template currly.BuildCurl
+		args     []currly.Arg
+		attempts int
+	}{
+		{post, nil, 1},
+		{post.Idempotent(), nil, 3},
+		{post, []currly.Arg{currly.IdempotentArg(true)}, 3},
+		{currly.Builder().GET().HTTPS().Localhost(), nil, 3},
+		{currly.Builder().GET().HTTPS().Localhost().NonIdempotent(), nil, 1},
+		{post.Idempotent(), []currly.Arg{currly.IdempotentArg(false)}, 1},
+	}
+
+	for i, c := range cases {
+		curl, err := c.template.Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		calls = 0
+
+		if _, _, err = curl(con, c.args...); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if c.attempts != calls {
+			t.Errorf("Unexpected number of attempts in case %v (expected: %v, actual: %v).", i, c.attempts, calls)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+)
+
+func RoundTripper(b BuildCurl, con Connector) http.RoundTripper {
+	ct, ok := b.(curlTemplate)
+
+	if !ok {
+		ct = curlTemplate{error: errors.New("currly: template was not created by currly")}
+	}
+
+	if ct.error == nil {
+		ct.chain = templateChain(ct.middleware)
+	}
+
+	return &templateRoundTripper{ct: ct, con: con, ar: newAuthRefresher(ct.refreshAuth)}
+}
+
+func Client(b BuildCurl, con Connector) *http.Client {
+	return &http.Client{Transport: RoundTripper(b, con)}
+}
+
+type templateRoundTripper struct {
+	ct  curlTemplate
+	con Connector
+	ar  *authRefresher
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (rt *templateRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	ct := rt.ct
+
+	if ct.error != nil {
+		closeRequestBody(r)
+
+		return nil, ct.error
+	}
+
+	clock := clockOrSystem(ct.clock)
+	start := clock.Now()
+	resp, err := rt.roundTrip(r)
+
+	if ct.stats != nil {
+		sc := 0
+
+		if resp != nil {
+			sc = resp.StatusCode
+		}
+
+		ct.stats.record(clock.Now().Sub(start), sc, ct.labels, err)
+	}
+
+	return resp, err
+}
+
+func (rt *templateRoundTripper) roundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := rt.send(r)
+
+	if err != nil || rt.ar == nil || resp.StatusCode != http.StatusUnauthorized || !replayable(r) {
+		return resp, err
+	}
+
+	if err := rt.ar.renew(r.Context()); err != nil {
+		DrainBody(resp.Body)
+
+		return nil, err
+	}
+
+	DrainBody(resp.Body)
+
+	if r.GetBody != nil {
+		r = r.Clone(r.Context())
+
+		if r.Body, err = r.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rt.send(r)
+}
+
+func (rt *templateRoundTripper) send(r *http.Request) (*http.Response, error) {
+	ct := rt.ct
+	ctx := withCallInfo(r.Context(), callInfo{urlTemplate: r.URL.Path, attempt: 1, redaction: ct.redaction, chain: append(Chain(ct.chain), Chain(rt.con)...), idempotency: ct.idempotency,
+		templateName: ct.templateName, version: ct.version, deprecation: ct.deprecation})
+	cancel := context.CancelFunc(func() {})
+
+	if len(ct.tlsServerName) > 0 {
+		ctx = context.WithValue(ctx, tlsServerNameKey{}, ct.tlsServerName)
+	}
+
+	if ct.timeout > 0 {
+		ctx, cancel = withTimeout(ctx, ct.clock, ct.timeout)
+	}
+
+	var phases *phaseTimeouts
+
+	if ct.connectTimeout > 0 || ct.headerTimeout > 0 || ct.bodyTimeout > 0 {
+		phases = newPhaseTimeouts(ctx, ct.clock)
+		ctx = phases.ctx
+	}
+
+	if ct.connectTimeout > 0 {
+		ctx = httptrace.WithClientTrace(ctx, phases.connectTrace(ct.connectTimeout))
+	}
+
+	release, err := acquire(ctx, ct.inFlight, ct.saturation)
+
+	if err != nil {
+		closeRequestBody(r)
+		phases.close()
+		cancel()
+
+		return nil, err
+	}
+
+	stopBodyTimeout := func() {}
+	done := func() {
+		stopBodyTimeout()
+		release()
+		phases.close()
+		cancel()
+	}
+	req, err := rt.prepare(r.Clone(ctx))
+
+	if err != nil {
+		closeRequestBody(r)
+		done()
+
+		return nil, err
+	}
+
+	stopHeaderTimeout := phases.start(ct.headerTimeout, ErrResponseHeaderTimeout)
+	resp, err := rt.transmit(req)
+
+	stopHeaderTimeout()
+
+	if err != nil {
+		done()
+
+		return nil, err
+	}
+
+	stopBodyTimeout = phases.start(ct.bodyTimeout, ErrBodyTimeout)
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: done}
+
+	return resp, nil
+}
+
+func (rt *templateRoundTripper) prepare(r *http.Request) (*http.Request, error) {
+	ct := rt.ct
+	scratch := curlTemplate{header: make(http.Header)}
+
+	for _, a := range rt.ar.prepend(nil) {
+		if err := a.applyTo(&scratch); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, h := range []http.Header{scratch.header, ct.header} {
+		for k, v := range h {
+			if _, ok := r.Header[k]; !ok {
+				r.Header[k] = append([]string(nil), v...)
+			}
+		}
+	}
+
+	for _, c := range []credentials{scratch.credentials, ct.credentials} {
+		if c != emptyCredentials && len(r.Header.Get("Authorization")) == 0 {
+			r.SetBasicAuth(c.username, c.password)
+		}
+	}
+
+	if ct.credentialProvider != nil {
+		pc := curlTemplate{ctx: r.Context(), header: r.Header, credentialProvider: ct.credentialProvider}
+
+		if err := applyCredentialProvider(&pc, r.URL.Hostname()); err != nil {
+			return nil, err
+		}
+
+		if pc.credentials != emptyCredentials {
+			r.SetBasicAuth(pc.credentials.username, pc.credentials.password)
+		}
+	}
+
+	if len(ct.hostHeader) > 0 {
+		r.Host = ct.hostHeader
+	}
+
+	for _, h := range ct.beforeSend {
+		if err := h(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (rt *templateRoundTripper) transmit(r *http.Request) (*http.Response, error) {
+	ct := rt.ct
+	con := rt.con
+
+	if ct.chain != nil {
+		r = r.WithContext(context.WithValue(r.Context(), callConnectorKey{}, con))
+		con = ct.chain
+	}
+
+	resp, err := con.Send(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range ct.afterReceive {
+		if err := h(resp); err != nil {
+			DrainBody(resp.Body)
+
+			return nil, err
+		}
+	}
+
+	for _, p := range ct.processors {
+		processed, err := p(resp)
+
+		if err != nil {
+			DrainBody(resp.Body)
+
+			return nil, err
+		}
+
+		resp = processed
+	}
+
+	return resp, nil
+}
+
+func (rb *releasingBody) Close() error {
+	err := rb.ReadCloser.Close()
+
+	rb.once.Do(rb.release)
+
+	return err
+}
+
+func closeRequestBody(r *http.Request) {
+	if r.Body != nil {
+		r.Body.Close()
+	}
+}
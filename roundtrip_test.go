@@ -0,0 +1,131 @@
+package currly_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestClientAppliesTemplatePolicies(t *testing.T) {
+	var calls int
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		resp := okResponse(r, "payload")
+
+		switch {
+		case "Bearer fresh" != r.Header.Get("Authorization"):
+			resp.StatusCode = http.StatusUnauthorized
+		case calls < 3:
+			resp.StatusCode = http.StatusServiceUnavailable
+		case "sdk/1.0" != r.Header.Get("User-Agent") || "application/json" != r.Header.Get("Accept"):
+			resp.StatusCode = http.StatusBadRequest
+		}
+
+		return resp, nil
+	})
+	template := currly.Builder().GET().HTTPS().Localhost().
+		Header(http.Header{"Accept": {"application/json"}, "User-Agent": {"currly"}}).
+		OnUnauthorized(func(ctx context.Context) (currly.Arg, error) {
+			return currly.BearerTokenArg("fresh"), nil
+		}).
+		Use(currly.RetryMiddleware(currly.RetryPolicy{MaxAttempts: 2}))
+	client := currly.Client(template, con)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/v1/things", nil)
+
+	req.Header.Set("User-Agent", "sdk/1.0")
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		t.Fatalf("Sending the request returned an unexpected error: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	bs, _ := ioutil.ReadAll(resp.Body)
+
+	if http.StatusOK != resp.StatusCode || "payload" != string(bs) {
+		t.Errorf("Unexpected response (expected: %v %v, actual: %v %v).", http.StatusOK, "payload", resp.StatusCode, string(bs))
+	}
+
+	if 3 != calls {
+		t.Errorf("Unexpected number of calls (expected: %v, actual: %v).", 3, calls)
+	}
+
+	if len(req.Header.Get("Authorization")) > 0 {
+		t.Errorf("The caller's request should not be modified.")
+	}
+}
+
+func TestRoundTripperHoldsConcurrencySlotUntilBodyIsClosed(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	rt := currly.RoundTripper(currly.Builder().GET().HTTPS().Localhost().MaxConcurrent(1).FailFastWhenSaturated(), con)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+	resp, err := rt.RoundTrip(req)
+
+	if err != nil {
+		t.Fatalf("Sending the request returned an unexpected error: %v", err)
+	}
+
+	if _, err := rt.RoundTrip(req); !errors.Is(err, currly.ErrTooManyInFlight) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", currly.ErrTooManyInFlight, err)
+	}
+
+	resp.Body.Close()
+
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Errorf("Sending the request after closing the body returned an unexpected error: %v", err)
+	}
+}
+
+func TestRoundTripperClosesRequestBodiesOnEarlyErrors(t *testing.T) {
+	failing := currly.CredentialProviderFunc(func(ctx context.Context, host string) (currly.Credential, error) {
+		return currly.Credential{}, errors.New("vault unavailable")
+	})
+	rt := currly.RoundTripper(currly.Builder().POST().HTTPS().Localhost().CredentialsFrom(failing), connectorFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("A request with failing credentials should not be sent.")
+
+		return okResponse(r, ""), nil
+	}))
+	body := &trackingBody{Reader: strings.NewReader("payload")}
+	r, _ := http.NewRequest(http.MethodPost, "https://localhost/users", body)
+
+	if resp, err := rt.RoundTrip(r); err == nil || resp != nil {
+		t.Errorf("Unexpected round trip result (response: %v, error: %v).", resp, err)
+	}
+
+	if !body.closed {
+		t.Errorf("The request body should be closed on errors.")
+	}
+}
+
+func TestRoundTripperDrainsUnauthorizedResponsesWhenRenewalFails(t *testing.T) {
+	body := &trackingBody{Reader: strings.NewReader("denied")}
+	refresh := currly.AuthRefreshFunc(func(ctx context.Context) (currly.Arg, error) {
+		return nil, errors.New("refresh failed")
+	})
+	rt := currly.RoundTripper(currly.Builder().GET().HTTPS().Localhost().OnUnauthorized(refresh), connectorFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r, "")
+		resp.StatusCode = http.StatusUnauthorized
+		resp.Body = body
+
+		return resp, nil
+	}))
+	r, _ := http.NewRequest(http.MethodGet, "https://localhost/users", nil)
+
+	if resp, err := rt.RoundTrip(r); err == nil || resp != nil {
+		t.Errorf("Unexpected round trip result (response: %v, error: %v).", resp, err)
+	}
+
+	if !body.closed {
+		t.Errorf("The unauthorized response body should be closed.")
+	}
+}
@@ -0,0 +1,108 @@
+package currly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var ErrConnectorClosed = errors.New("currly: connector is closed")
+
+func CloseConnector(ctx context.Context, con Connector) error {
+	cc, ok := con.(ClosableConnector)
+
+	if !ok {
+		return nil
+	}
+
+	return cc.Close(ctx)
+}
+
+type ClosableConnector interface {
+	Connector
+	Close(ctx context.Context) error
+}
+
+type inFlightTracker struct {
+	mutex  sync.Mutex
+	closed bool
+	count  int
+	idle   chan struct{}
+}
+
+func (cc *clientConnector) Close(ctx context.Context) error {
+	err := cc.inFlight.wait(ctx)
+
+	cc.CloseIdleConnections()
+	cc.serverNameClients.Range(func(_, c interface{}) bool {
+		c.(*http.Client).CloseIdleConnections()
+
+		return true
+	})
+
+	return err
+}
+
+func (cc *chainConnector) Close(ctx context.Context) error {
+	return CloseConnector(ctx, cc.next)
+}
+
+func (t *inFlightTracker) track(resp *http.Response, err error) (*http.Response, error) {
+	if err != nil || resp.Body == nil {
+		t.release()
+
+		return resp, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.release}
+
+	return resp, nil
+}
+
+func (t *inFlightTracker) acquire() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.closed {
+		return ErrConnectorClosed
+	}
+
+	t.count++
+
+	return nil
+}
+
+func (t *inFlightTracker) release() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.count--
+
+	if t.closed && t.count == 0 {
+		close(t.idle)
+	}
+}
+
+func (t *inFlightTracker) wait(ctx context.Context) error {
+	t.mutex.Lock()
+
+	if !t.closed {
+		t.closed = true
+		t.idle = make(chan struct{})
+
+		if t.count == 0 {
+			close(t.idle)
+		}
+	}
+
+	idle := t.idle
+	t.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
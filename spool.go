@@ -0,0 +1,74 @@
+package currly
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+func SpoolExtractor(threshold int64) ResultExtractor {
+	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		buf := new(bytes.Buffer)
+		n, err := io.Copy(buf, io.LimitReader(r.Body, threshold+1))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if n <= threshold {
+			return &SpooledBody{ReadSeeker: bytes.NewReader(buf.Bytes()), size: n}, nil
+		}
+
+		f, err := ioutil.TempFile("", "currly-spool-")
+
+		if err != nil {
+			return nil, err
+		}
+
+		sb := &SpooledBody{ReadSeeker: f, file: f}
+
+		if sb.size, err = io.Copy(f, io.MultiReader(buf, r.Body)); err != nil {
+			sb.Close()
+
+			return nil, err
+		}
+
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			sb.Close()
+
+			return nil, err
+		}
+
+		return sb, nil
+	})
+}
+
+type SpooledBody struct {
+	io.ReadSeeker
+	file *os.File
+	size int64
+}
+
+func (sb *SpooledBody) Size() int64 {
+	return sb.size
+}
+
+func (sb *SpooledBody) OnDisk() bool {
+	return sb.file != nil
+}
+
+func (sb *SpooledBody) Close() error {
+	if sb.file == nil {
+		return nil
+	}
+
+	err := sb.file.Close()
+
+	if rerr := os.Remove(sb.file.Name()); err == nil {
+		err = rerr
+	}
+
+	return err
+}
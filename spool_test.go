@@ -0,0 +1,48 @@
+package currly_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestSpoolExtractorKeepsSmallBodiesInMemoryAndSpoolsLargeOnes(t *testing.T) {
+	for _, body := range []string{"small", strings.Repeat("large", 100)} {
+		con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+			return okResponse(r, body), nil
+		})
+		curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.SpoolExtractor(64)).Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		_, res, err := curl(con)
+
+		if err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		sb := res.(*currly.SpooledBody)
+		bs, _ := ioutil.ReadAll(sb)
+
+		if body != string(bs) {
+			t.Errorf("Unexpected spooled body (expected: %v, actual: %v).", body, string(bs))
+		}
+
+		if (len(body) > 64) != sb.OnDisk() {
+			t.Errorf("Unexpected spool location for %v bytes (on disk: %v).", len(body), sb.OnDisk())
+		}
+
+		if int64(len(body)) != sb.Size() {
+			t.Errorf("Unexpected spooled size (expected: %v, actual: %v).", len(body), sb.Size())
+		}
+
+		if err := sb.Close(); err != nil {
+			t.Errorf("Closing the spooled body returned an unexpected error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package currly
+
+import (
+	"expvar"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const latencySampleSize = 1024
+
+var publishMutex sync.Mutex
+
+type Stats struct {
+	mutex       sync.Mutex
+	count       int64
+	errors      int64
+	statusCodes map[int]int64
+	labels      map[string]int64
+	latencies   []time.Duration
+	next        int
+}
+
+type StatsSnapshot struct {
+	Count       int64
+	Errors      int64
+	P50         time.Duration
+	P95         time.Duration
+	P99         time.Duration
+	StatusCodes map[int]int64
+	Labels      map[string]int64
+}
+
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ss := StatsSnapshot{
+		Count:       s.count,
+		Errors:      s.errors,
+		StatusCodes: make(map[int]int64, len(s.statusCodes)),
+		Labels:      make(map[string]int64, len(s.labels)),
+	}
+
+	for k, v := range s.statusCodes {
+		ss.StatusCodes[k] = v
+	}
+
+	for k, v := range s.labels {
+		ss.Labels[k] = v
+	}
+
+	ls := make([]time.Duration, len(s.latencies))
+
+	copy(ls, s.latencies)
+	sort.Slice(ls, func(i, j int) bool { return ls[i] < ls[j] })
+
+	ss.P50 = percentile(ls, 50)
+	ss.P95 = percentile(ls, 95)
+	ss.P99 = percentile(ls, 99)
+
+	return ss
+}
+
+func (s *Stats) Publish(name string) error {
+	prefix := "currly." + name + "."
+	vars := map[string]func(ss StatsSnapshot) interface{}{
+		"requests":       func(ss StatsSnapshot) interface{} { return ss.Count },
+		"errors":         func(ss StatsSnapshot) interface{} { return ss.Errors },
+		"latency_p50_ms": func(ss StatsSnapshot) interface{} { return milliseconds(ss.P50) },
+		"latency_p95_ms": func(ss StatsSnapshot) interface{} { return milliseconds(ss.P95) },
+		"latency_p99_ms": func(ss StatsSnapshot) interface{} { return milliseconds(ss.P99) },
+		"status_codes":   func(ss StatsSnapshot) interface{} { return statusCodeCounts(ss.StatusCodes) },
+		"labels":         func(ss StatsSnapshot) interface{} { return ss.Labels },
+	}
+
+	publishMutex.Lock()
+	defer publishMutex.Unlock()
+
+	for k := range vars {
+		if expvar.Get(prefix+k) != nil {
+			return fmt.Errorf("currly: expvar '%v' is already published", prefix+k)
+		}
+	}
+
+	for k, f := range vars {
+		f := f
+
+		expvar.Publish(prefix+k, expvar.Func(func() interface{} { return f(s.Snapshot()) }))
+	}
+
+	return nil
+}
+
+func (s *Stats) record(latency time.Duration, statusCode int, labels map[string]string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.count++
+
+	if err != nil {
+		s.errors++
+	}
+
+	if statusCode > 0 {
+		if s.statusCodes == nil {
+			s.statusCodes = make(map[int]int64)
+		}
+
+		s.statusCodes[statusCode]++
+	}
+
+	for k, v := range labels {
+		if s.labels == nil {
+			s.labels = make(map[string]int64)
+		}
+
+		s.labels[k+"="+v]++
+	}
+
+	if len(s.latencies) < latencySampleSize {
+		s.latencies = append(s.latencies, latency)
+
+		return
+	}
+
+	s.latencies[s.next] = latency
+	s.next = (s.next + 1) % latencySampleSize
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := (len(sorted)*p+99)/100 - 1
+
+	if i < 0 {
+		i = 0
+	}
+
+	return sorted[i]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func statusCodeCounts(scs map[int]int64) map[string]int64 {
+	counts := make(map[string]int64, len(scs))
+
+	for k, v := range scs {
+		counts[strconv.Itoa(k)] = v
+	}
+
+	return counts
+}
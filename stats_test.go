@@ -0,0 +1,111 @@
+package currly_test
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+var publications atomic.Int64
+
+func TestStatsCollectsCallsErrorsAndStatusCodes(t *testing.T) {
+	fail := false
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+
+		return okResponse(r, ""), nil
+	})
+	stats := new(currly.Stats)
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Stats(stats).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con)
+	curl(con)
+
+	fail = true
+
+	curl(con)
+
+	ss := stats.Snapshot()
+
+	if 3 != ss.Count {
+		t.Errorf("Unexpected call count (expected: %v, actual: %v).", 3, ss.Count)
+	}
+
+	if 1 != ss.Errors {
+		t.Errorf("Unexpected error count (expected: %v, actual: %v).", 1, ss.Errors)
+	}
+
+	if 2 != ss.StatusCodes[http.StatusOK] {
+		t.Errorf("Unexpected status count (expected: %v, actual: %v).", 2, ss.StatusCodes[http.StatusOK])
+	}
+
+	if ss.P99 < ss.P50 {
+		t.Errorf("Unexpected latency percentiles (p50: %v, p99: %v).", ss.P50, ss.P99)
+	}
+}
+
+func TestStatsPublishExportsExpvars(t *testing.T) {
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	stats := new(currly.Stats)
+	name := fmt.Sprintf("users_get_%v", publications.Add(1))
+
+	if err := stats.Publish(name); err != nil {
+		t.Fatalf("Publishing the statistics returned an unexpected error: %v", err)
+	}
+
+	curl, err := currly.Builder().GET().HTTPS().Localhost().Stats(stats).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	curl(con)
+
+	v := expvar.Get("currly." + name + ".requests")
+
+	if v == nil {
+		t.Fatalf("The request counter should be published.")
+	}
+
+	if "1" != v.String() {
+		t.Errorf("Unexpected request counter (expected: %v, actual: %v).", "1", v.String())
+	}
+
+	if err := stats.Publish(name); err == nil {
+		t.Errorf("Publishing the same name twice should fail.")
+	}
+}
+
+func TestStatsPublishRejectsConcurrentDuplicates(t *testing.T) {
+	name := fmt.Sprintf("concurrent_%v", publications.Add(1))
+	errs := make(chan error, 8)
+
+	for i := 0; i < 8; i++ {
+		go func() { errs <- new(currly.Stats).Publish(name) }()
+	}
+
+	published := 0
+
+	for i := 0; i < 8; i++ {
+		if <-errs == nil {
+			published++
+		}
+	}
+
+	if 1 != published {
+		t.Errorf("Unexpected number of successful publications (expected: %v, actual: %v).", 1, published)
+	}
+}
@@ -0,0 +1,203 @@
+package currly
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func NDJSONExtractor(handle func(record json.RawMessage) error) ResultExtractor {
+	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		d := json.NewDecoder(r.Body)
+		n := 0
+
+		for {
+			var record json.RawMessage
+			err := d.Decode(&record)
+
+			if err == io.EOF {
+				return n, nil
+			}
+
+			if err != nil {
+				return n, err
+			}
+
+			if err := handle(record); err != nil {
+				return n, err
+			}
+
+			n++
+		}
+	})
+}
+
+func JSONArrayExtractor(handle func(element json.RawMessage) error) ResultExtractor {
+	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		d := json.NewDecoder(r.Body)
+		n := 0
+
+		if err := expectDelim(d, '['); err != nil {
+			return n, err
+		}
+
+		for d.More() {
+			var element json.RawMessage
+
+			if err := d.Decode(&element); err != nil {
+				return n, err
+			}
+
+			if err := handle(element); err != nil {
+				return n, err
+			}
+
+			n++
+		}
+
+		if err := expectDelim(d, ']'); err != nil {
+			return n, err
+		}
+
+		if _, err := d.Token(); err != io.EOF {
+			return n, errors.New("currly: unexpected data after the JSON array")
+		}
+
+		return n, nil
+	})
+}
+
+func SSEExtractor(handle func(e Event) error) ResultExtractor {
+	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		br := bufio.NewReader(r.Body)
+		n := 0
+		var e Event
+		var data []string
+
+		for {
+			line, err := br.ReadString('\n')
+
+			if err == io.EOF && len(line) == 0 {
+				return n, nil
+			}
+
+			if err != nil && err != io.EOF {
+				return n, err
+			}
+
+			line = strings.TrimRight(line, "\r\n")
+
+			if len(line) == 0 {
+				if len(data) > 0 {
+					e.Data = strings.Join(data, "\n")
+
+					if err := handle(e); err != nil {
+						return n, err
+					}
+
+					n++
+				}
+
+				e = Event{ID: e.ID}
+				data = nil
+
+				continue
+			}
+
+			if strings.HasPrefix(line, ":") {
+				continue
+			}
+
+			field, value := line, ""
+
+			if i := strings.Index(line, ":"); i >= 0 {
+				field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+			}
+
+			switch field {
+			case "data":
+				data = append(data, value)
+			case "event":
+				e.Event = value
+			case "id":
+				e.ID = value
+			case "retry":
+				if ms, err := strconv.Atoi(value); err == nil {
+					e.Retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+	})
+}
+
+func WriterExtractor(w io.Writer) ResultExtractor {
+	return ResultExtractorFunc(func(r *http.Response) (interface{}, error) {
+		return io.Copy(w, r.Body)
+	})
+}
+
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+type contextBody struct {
+	ctx  context.Context
+	body io.ReadCloser
+	stop func() bool
+}
+
+func newContextBody(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	if body == nil || ctx.Done() == nil {
+		return body
+	}
+
+	return &contextBody{
+		ctx:  ctx,
+		body: body,
+		stop: context.AfterFunc(ctx, func() { body.Close() }),
+	}
+}
+
+func (cb *contextBody) Read(p []byte) (int, error) {
+	n, err := cb.body.Read(p)
+
+	if err != nil && cb.ctx.Err() != nil {
+		return n, cb.ctx.Err()
+	}
+
+	return n, err
+}
+
+func (cb *contextBody) Close() error {
+	cb.stop()
+
+	return cb.body.Close()
+}
+
+func expectDelim(d *json.Decoder, delim json.Delim) error {
+	t, err := d.Token()
+
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if t != delim {
+		return fmt.Errorf("currly: expected '%v' in JSON array, found %v", delim, t)
+	}
+
+	return nil
+}
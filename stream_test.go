@@ -0,0 +1,241 @@
+package currly_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func slowConnector(lines ...string) currly.Connector {
+	return connectorFunc(func(r *http.Request) (*http.Response, error) {
+		pr, pw := io.Pipe()
+
+		go func() {
+			for _, l := range lines {
+				if _, err := io.WriteString(pw, l+"\n"); err != nil {
+					return
+				}
+			}
+
+			<-r.Context().Done()
+			pw.Close()
+		}()
+
+		resp := okResponse(r, "")
+		resp.Body = pr
+
+		return resp, nil
+	})
+}
+
+func TestNDJSONExtractorStopsPromptlyOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var records []string
+
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.NDJSONExtractor(func(record json.RawMessage) error {
+			records = append(records, string(record))
+
+			if len(records) == 2 {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					cancel()
+				}()
+			}
+
+			return nil
+		})).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, _, err := curl(slowConnector(`{"id":1}`, `{"id":2}`), currly.ContextArg(ctx))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Unexpected error (expected: %v, actual: %v).", context.Canceled, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("The extractor did not observe the cancellation.")
+	}
+
+	if 2 != len(records) {
+		t.Errorf("Unexpected record count (expected: %v, actual: %v).", 2, len(records))
+	}
+}
+
+func TestWriterExtractorStopsOnTimeout(t *testing.T) {
+	buf := new(bytes.Buffer)
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		Timeout(20 * time.Millisecond).
+		ResultExtractor(currly.WriterExtractor(buf)).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, _, err = curl(slowConnector("chunk"))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error (expected: %v, actual: %v).", context.DeadlineExceeded, err)
+	}
+
+	if "chunk\n" != buf.String() {
+		t.Errorf("Unexpected streamed content (expected: %q, actual: %q).", "chunk\n", buf.String())
+	}
+}
+
+func TestWriterExtractorCopiesBody(t *testing.T) {
+	buf := new(bytes.Buffer)
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, "payload"), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().ResultExtractor(currly.WriterExtractor(buf)).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, n, err := curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if int64(7) != n || "payload" != buf.String() {
+		t.Errorf("Unexpected result (expected: %v, actual: %v).", 7, n)
+	}
+}
+
+func TestSSEExtractorParsesEventsAndStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var events []currly.Event
+
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.SSEExtractor(func(e currly.Event) error {
+			events = append(events, e)
+
+			if len(events) == 2 {
+				cancel()
+			}
+
+			return nil
+		})).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+	con := slowConnector(": keep-alive", "id: 1", "event: greeting", "data: hello", "data: world", "", "retry: 1500", "data: again", "")
+
+	go func() {
+		_, _, err := curl(con, currly.ContextArg(ctx))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Unexpected error (expected: %v, actual: %v).", context.Canceled, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("The extractor did not observe the cancellation.")
+	}
+
+	expected := []currly.Event{
+		{ID: "1", Event: "greeting", Data: "hello\nworld"},
+		{ID: "1", Data: "again", Retry: 1500 * time.Millisecond},
+	}
+
+	if fmt.Sprint(expected) != fmt.Sprint(events) {
+		t.Errorf("Unexpected events (expected: %v, actual: %v).", expected, events)
+	}
+}
+
+func TestJSONArrayExtractorHandlesElementsOneByOne(t *testing.T) {
+	var elements []string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ` [{"id":1}, 2, "three", [4]] `), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		ResultExtractor(currly.JSONArrayExtractor(func(element json.RawMessage) error {
+			elements = append(elements, string(element))
+
+			return nil
+		})).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, n, err := curl(con)
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	expected := fmt.Sprint([]string{`{"id":1}`, `2`, `"three"`, `[4]`})
+
+	if 4 != n || expected != fmt.Sprint(elements) {
+		t.Errorf("Unexpected elements (expected: %v, actual: %v).", expected, elements)
+	}
+}
+
+func TestJSONArrayExtractorRejectsMalformedArrays(t *testing.T) {
+	stop := errors.New("stop")
+	cases := map[string]error{
+		`{"id":1}`:  nil,
+		`[1, 2`:     nil,
+		`[1] [2]`:   nil,
+		``:          io.ErrUnexpectedEOF,
+		`[1, 2, 3]`: stop,
+	}
+
+	for body, expected := range cases {
+		con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+			return okResponse(r, body), nil
+		})
+		curl, err := currly.Builder().GET().HTTPS().Localhost().
+			ResultExtractor(currly.JSONArrayExtractor(func(element json.RawMessage) error {
+				if "3" == string(element) {
+					return stop
+				}
+
+				return nil
+			})).
+			Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		_, _, err = curl(con)
+
+		if err == nil || expected != nil && !errors.Is(err, expected) {
+			t.Errorf("Unexpected error for body %q (expected: %v, actual: %v).", body, expected, err)
+		}
+	}
+}
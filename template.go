@@ -0,0 +1,146 @@
+package currly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+func TemplateBodyArg(tmpl string, data map[string]interface{}) Arg {
+	var t *template.Template
+	var err error
+
+	once := sync.Once{}
+
+	return argFunc(func(ct *curlTemplate) error {
+		once.Do(func() { t, err = parseBodyTemplate(tmpl) })
+
+		if err != nil {
+			return fmt.Errorf("currly: body template is invalid: %w", err)
+		}
+
+		buf := new(bytes.Buffer)
+
+		if err := t.Execute(buf, data); err != nil {
+			return fmt.Errorf("currly: rendering the body template failed: %w", err)
+		}
+
+		if !json.Valid(buf.Bytes()) {
+			return errors.New("currly: rendered body template is not valid JSON")
+		}
+
+		if ct.header == nil {
+			ct.header = make(http.Header)
+		}
+
+		ct.header.Set("Content-Type", "application/json; charset=utf-8")
+		ct.body = newJSONBody(buf.Bytes())
+		ct.contentLength = int64(buf.Len())
+
+		return nil
+	})
+}
+
+func parseBodyTemplate(tmpl string) (*template.Template, error) {
+	t, err := template.New("body").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"json": jsonLiteral, "escapeJSON": escapeJSON}).
+		Parse(tmpl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range t.Templates() {
+		if d.Tree != nil {
+			escapeActions(d.Tree, d.Tree.Root)
+		}
+	}
+
+	return t, nil
+}
+
+func escapeActions(tree *parse.Tree, n parse.Node) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, c := range n.Nodes {
+			escapeActions(tree, c)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 || endsWithJSON(n.Pipe) {
+			return
+		}
+
+		id := parse.NewIdentifier("escapeJSON").SetTree(tree).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{id}})
+	case *parse.IfNode:
+		escapeActions(tree, n.List)
+		escapeActions(tree, n.ElseList)
+	case *parse.RangeNode:
+		escapeActions(tree, n.List)
+		escapeActions(tree, n.ElseList)
+	case *parse.WithNode:
+		escapeActions(tree, n.List)
+		escapeActions(tree, n.ElseList)
+	}
+}
+
+func endsWithJSON(p *parse.PipeNode) bool {
+	if len(p.Cmds) == 0 {
+		return false
+	}
+
+	id, ok := p.Cmds[len(p.Cmds)-1].Args[0].(*parse.IdentifierNode)
+
+	return ok && id.Ident == "json"
+}
+
+func jsonLiteral(v interface{}) (string, error) {
+	bs, err := json.Marshal(v)
+
+	return string(bs), err
+}
+
+func escapeJSON(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "null", nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return "null", nil
+	case reflect.String:
+		bs, _ := json.Marshal(rv.String())
+
+		return string(bs[1 : len(bs)-1]), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		bs, err := json.Marshal(rv.Float())
+
+		return string(bs), err
+	}
+
+	return "", fmt.Errorf("currly: value of type %T must be rendered with the json function", v)
+}
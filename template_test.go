@@ -0,0 +1,131 @@
+package currly_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestTemplateBodyArgRendersEscapedJSON(t *testing.T) {
+	var body, contentType string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		body, contentType = string(bs), r.Header.Get("Content-Type")
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().PathSegment("users").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	tmpl := `{"name": "{{.name}}", "age": {{.age}}, "tags": {{json .tags}}{{if .admin}}, "role": "admin"{{end}}}`
+	data := map[string]interface{}{"name": "Bob \"The Builder\"\n", "age": 42, "tags": []string{"a", "<b>"}, "admin": true}
+
+	if _, _, err = curl(con, currly.TemplateBodyArg(tmpl, data)); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	var actual map[string]interface{}
+
+	if err := json.Unmarshal([]byte(body), &actual); err != nil {
+		t.Fatalf("The rendered body should be valid JSON: %v (%v)", err, body)
+	}
+
+	if "Bob \"The Builder\"\n" != actual["name"] || float64(42) != actual["age"] || "admin" != actual["role"] {
+		t.Errorf("Unexpected rendered body: %v.", body)
+	}
+
+	if tags, ok := actual["tags"].([]interface{}); !ok || 2 != len(tags) || "<b>" != tags[1] {
+		t.Errorf("Unexpected rendered tags: %v.", actual["tags"])
+	}
+
+	if "application/json; charset=utf-8" != contentType {
+		t.Errorf("Unexpected content type (expected: %v, actual: %v).", "application/json; charset=utf-8", contentType)
+	}
+}
+
+func TestTemplateBodyArgEscapesEveryAction(t *testing.T) {
+	type ID string
+
+	type User struct {
+		Name string
+		ID   *ID
+		Tags []map[string]string
+	}
+
+	var body string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		body = string(bs)
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().POST().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	id := ID(`7", "admin": "true`)
+	user := User{Name: `x", "admin": "true`, ID: &id, Tags: []map[string]string{{"k": `v"}`}}}
+	tmpl := `{"name": "{{.u.Name}}", "id": "{{.u.ID}}", "named": "{{.id}}"{{range .u.Tags}}, "tag": "{{.k}}"{{end}}, "tags": {{json .u.Tags}}}`
+
+	if _, _, err = curl(con, currly.TemplateBodyArg(tmpl, map[string]interface{}{"u": user, "id": id})); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	var actual map[string]interface{}
+
+	if err := json.Unmarshal([]byte(body), &actual); err != nil {
+		t.Fatalf("The rendered body should be valid JSON: %v (%v)", err, body)
+	}
+
+	expected := map[string]interface{}{"name": user.Name, "id": string(id), "named": string(id), "tag": `v"}`}
+
+	for k, v := range expected {
+		if v != actual[k] {
+			t.Errorf("Unexpected rendered field '%v' (expected: %v, actual: %v).", k, v, actual[k])
+		}
+	}
+
+	if _, ok := actual["admin"]; ok {
+		t.Errorf("The rendered body should not contain injected fields: %v.", body)
+	}
+
+	if _, _, err = curl(con, currly.TemplateBodyArg(`{"u": "{{.u}}"}`, map[string]interface{}{"u": user})); err == nil {
+		t.Errorf("Rendering a struct without the json function should fail.")
+	}
+}
+
+func TestTemplateBodyArgRejectsInvalidTemplates(t *testing.T) {
+	curl, err := currly.Builder().POST().HTTPS().Localhost().Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	cases := []struct {
+		tmpl string
+		data map[string]interface{}
+	}{
+		{`{"id": {{.id}`, map[string]interface{}{"id": 1}},
+		{`{"id": {{.missing}}}`, map[string]interface{}{"id": 1}},
+		{`{"id": {{.id}}`, map[string]interface{}{"id": 1}},
+	}
+
+	for i, c := range cases {
+		if _, _, err := curl(con, currly.TemplateBodyArg(c.tmpl, c.data)); err == nil {
+			t.Errorf("Rendering template %v should fail.", i)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package currly
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+var (
+	ErrConnectTimeout        error = &timeoutError{"currly: connect timeout exceeded"}
+	ErrResponseHeaderTimeout error = &timeoutError{"currly: response header timeout exceeded"}
+	ErrBodyTimeout           error = &timeoutError{"currly: body read timeout exceeded"}
+)
+
+type timeoutError struct {
+	msg string
+}
+
+type phaseTimeouts struct {
+	ctx   *clockContext
+	clock Clock
+}
+
+func (e *timeoutError) Error() string {
+	return e.msg
+}
+
+func (e *timeoutError) Timeout() bool {
+	return true
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+func newPhaseTimeouts(ctx context.Context, c Clock) *phaseTimeouts {
+	cc := &clockContext{parent: ctx, done: make(chan struct{})}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			cc.cancel(ctx.Err())
+		case <-cc.done:
+		}
+	}()
+
+	return &phaseTimeouts{ctx: cc, clock: clockOrSystem(c)}
+}
+
+func (pt *phaseTimeouts) start(d time.Duration, err error) func() {
+	if pt == nil || d <= 0 {
+		return func() {}
+	}
+
+	expired := pt.clock.After(d)
+	stopped := make(chan struct{})
+	once := sync.Once{}
+
+	go func() {
+		select {
+		case <-expired:
+			pt.ctx.cancel(err)
+		case <-stopped:
+		case <-pt.ctx.done:
+		}
+	}()
+
+	return func() { once.Do(func() { close(stopped) }) }
+}
+
+func (pt *phaseTimeouts) connectTrace(d time.Duration) *httptrace.ClientTrace {
+	var mutex sync.Mutex
+	var stop func()
+
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if stop == nil {
+				stop = pt.start(d, ErrConnectTimeout)
+			}
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if stop != nil {
+				stop()
+			}
+		},
+	}
+}
+
+func (pt *phaseTimeouts) close() {
+	if pt != nil {
+		pt.ctx.cancel(context.Canceled)
+	}
+}
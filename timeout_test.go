@@ -0,0 +1,96 @@
+package currly_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+	"github.com/DrDoofenshmirtz/currly/currlytest"
+)
+
+func TestPhaseTimeoutsFailTheMatchingPhase(t *testing.T) {
+	connecting := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		if trace := httptrace.ContextClientTrace(r.Context()); trace != nil && trace.GetConn != nil {
+			trace.GetConn(r.URL.Host)
+		}
+
+		<-r.Context().Done()
+
+		return nil, r.Context().Err()
+	})
+	waiting := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+
+		return nil, r.Context().Err()
+	})
+	streaming := slowConnector("chunk")
+	cases := []struct {
+		con      currly.Connector
+		waiters  int
+		advance  time.Duration
+		expected error
+	}{
+		{connecting, 2, time.Second, currly.ErrConnectTimeout},
+		{waiting, 1, time.Minute, currly.ErrResponseHeaderTimeout},
+		{streaming, 2, time.Hour, currly.ErrBodyTimeout},
+	}
+
+	for _, c := range cases {
+		clock := currlytest.NewFakeClock(time.Now())
+		curl, err := currly.Builder().GET().HTTPS().Localhost().
+			Clock(clock).
+			ConnectTimeout(time.Second).
+			ResponseHeaderTimeout(time.Minute).
+			BodyTimeout(time.Hour).
+			ResultExtractor(currly.WriterExtractor(ioutil.Discard)).
+			Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		done := make(chan error, 1)
+
+		go func() {
+			_, _, err := curl(c.con)
+			done <- err
+		}()
+
+		clock.BlockUntil(c.waiters)
+		clock.Advance(c.advance)
+
+		err = <-done
+
+		if !errors.Is(err, c.expected) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Unexpected error (expected: %v, actual: %v).", c.expected, err)
+		}
+	}
+}
+
+func TestPhaseTimeoutsDoNotFireAfterTheirPhase(t *testing.T) {
+	clock := currlytest.NewFakeClock(time.Now())
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, "done"), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().
+		Clock(clock).
+		ResponseHeaderTimeout(time.Second).
+		BodyTimeout(time.Second).
+		ResultExtractor(currly.PlainStringExtractor()).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	_, res, err := curl(con)
+
+	if err != nil || "done" != res {
+		t.Errorf("Unexpected result (result: %v, error: %v).", res, err)
+	}
+}
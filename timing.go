@@ -0,0 +1,98 @@
+package currly
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+func TimingArg(t *Timing) Arg {
+	return argFunc(func(ct *curlTemplate) error {
+		ct.timing = t
+
+		return nil
+	})
+}
+
+type Timing struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	FirstByte    time.Duration
+	Total        time.Duration
+}
+
+type timingRecorder struct {
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       Timing
+}
+
+func newTimingRecorder(start time.Time) *timingRecorder {
+	return &timingRecorder{start: start}
+}
+
+func (tr *timingRecorder) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+
+			tr.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+
+			tr.timing.DNS = time.Since(tr.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+
+			if tr.connectStart.IsZero() {
+				tr.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+
+			if err == nil && tr.timing.Connect == 0 {
+				tr.timing.Connect = time.Since(tr.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+
+			tr.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+
+			tr.timing.TLSHandshake = time.Since(tr.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+
+			tr.timing.FirstByte = time.Since(tr.start)
+		},
+	}
+}
+
+func (tr *timingRecorder) finish() Timing {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+
+	t := tr.timing
+	t.Total = time.Since(tr.start)
+
+	return t
+}
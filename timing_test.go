@@ -0,0 +1,47 @@
+package currly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestTimingArgRecordsDurations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 16)
+	curl, err := currly.Builder().GET().HTTP().Host(u.Hostname()).Port(uint(port)).Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	var timing currly.Timing
+
+	_, _, err = curl(currly.ConfiguredConnector(currly.ConnectorOptions{}), currly.TimingArg(&timing))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if timing.FirstByte < 10*time.Millisecond {
+		t.Errorf("Unexpected time to first byte (expected: >= %v, actual: %v).", 10*time.Millisecond, timing.FirstByte)
+	}
+
+	if timing.Total < timing.FirstByte {
+		t.Errorf("Unexpected total duration (expected: >= %v, actual: %v).", timing.FirstByte, timing.Total)
+	}
+
+	if timing.Connect <= 0 {
+		t.Errorf("Unexpected connect duration (expected: > 0, actual: %v).", timing.Connect)
+	}
+}
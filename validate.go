@@ -0,0 +1,303 @@
+package currly
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func ValidatedArg(inner Arg, rules ...Rule) Arg {
+	ba, ok := inner.(bindingArg)
+
+	return bindingArg{name: ba.name, value: ba.value, argFunc: func(ct *curlTemplate) error {
+		if !ok {
+			return &BindError{Reason: "argument does not bind a parameter value"}
+		}
+
+		for _, r := range rules {
+			if err := r(ba.value); err != nil {
+				return &BindError{ba.name, ba.value, err.Error()}
+			}
+		}
+
+		return ba.applyTo(ct)
+	}}
+}
+
+func NonEmpty() Rule {
+	return func(value string) error {
+		if len(value) == 0 {
+			return errors.New("must not be empty")
+		}
+
+		return nil
+	}
+}
+
+func MatchesPattern(pattern string) Rule {
+	re, err := regexp.Compile(pattern)
+
+	return func(value string) error {
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %q", pattern)
+		}
+
+		return nil
+	}
+}
+
+func MaxLength(n int) Rule {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Errorf("must not be longer than %v characters", n)
+		}
+
+		return nil
+	}
+}
+
+func InRange(min, max float64) Rule {
+	return func(value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+
+		if err != nil {
+			return errors.New("must be a number")
+		}
+
+		if f < min || f > max {
+			return fmt.Errorf("must be between %v and %v", min, max)
+		}
+
+		return nil
+	}
+}
+
+func OneOf(values ...string) Rule {
+	return func(value string) error {
+		for _, v := range values {
+			if v == value {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("must be one of %v", strings.Join(values, ", "))
+	}
+}
+
+type Rule func(value string) error
+
+type BuildError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+type BindError struct {
+	Param  string
+	Value  string
+	Reason string
+}
+
+type BindEvent struct {
+	Template string
+	Param    string
+	Missing  bool
+	Err      error
+}
+
+func (e *BuildError) Error() string {
+	return fmt.Sprintf("currly: invalid %v '%v': %v", e.Field, e.Value, e.Reason)
+}
+
+func (e *BindError) Error() string {
+	if len(e.Param) == 0 {
+		return fmt.Sprintf("currly: invalid argument: %v", e.Reason)
+	}
+
+	return fmt.Sprintf("currly: invalid argument for parameter '%v' ('%v'): %v", e.Param, e.Value, e.Reason)
+}
+
+func (ct curlTemplate) emitBindEvent(e BindEvent) {
+	for _, h := range ct.bindHooks {
+		h(e)
+	}
+}
+
+func paramOf(a Arg, err error) string {
+	var be *BindError
+
+	if errors.As(err, &be) && len(be.Param) > 0 {
+		return be.Param
+	}
+
+	if ba, ok := a.(bindingArg); ok {
+		return ba.name
+	}
+
+	return ""
+}
+
+func validate(ct curlTemplate) error {
+	if err := validateMethod(ct.method); err != nil {
+		return err
+	}
+
+	ut := ct.urlTemplate
+
+	if err := validateScheme(ut.scheme); err != nil {
+		return err
+	}
+
+	if err := validateHost(ut.host); err != nil {
+		return err
+	}
+
+	if ut.port > 65535 {
+		return &BuildError{"port", fmt.Sprint(ut.port), "must not exceed 65535"}
+	}
+
+	for _, v := range append(append([]variable(nil), ut.basePath...), ut.path...) {
+		if err := validateVariable("path", v); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range ut.query {
+		if err := validateVariable("query", v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateMethod(method string) error {
+	if len(method) == 0 {
+		return &BuildError{"method", method, "must not be empty"}
+	}
+
+	for _, r := range method {
+		if !isTokenChar(r) {
+			return &BuildError{"method", method, fmt.Sprintf("contains invalid character %q", r)}
+		}
+	}
+
+	return nil
+}
+
+func validateScheme(scheme string) error {
+	if len(scheme) == 0 {
+		return &BuildError{"scheme", scheme, "must not be empty"}
+	}
+
+	for i, r := range scheme {
+		if !isAlpha(r) && (i == 0 || (!isDigit(r) && !strings.ContainsRune("+-.", r))) {
+			return &BuildError{"scheme", scheme, fmt.Sprintf("contains invalid character %q", r)}
+		}
+	}
+
+	return nil
+}
+
+func validateHost(host string) error {
+	if len(host) == 0 {
+		return &BuildError{"host", host, "must not be empty"}
+	}
+
+	if strings.Contains(host, ":") {
+		if net.ParseIP(strings.SplitN(host, "%", 2)[0]) == nil {
+			return &BuildError{"host", host, "is not a valid IPv6 address"}
+		}
+
+		return nil
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if len(label) == 0 {
+			return &BuildError{"host", host, "must not contain empty labels"}
+		}
+	}
+
+	for _, r := range host {
+		if !isAlpha(r) && !isDigit(r) && !strings.ContainsRune("-._~", r) {
+			return &BuildError{"host", host, fmt.Sprintf("contains invalid character %q", r)}
+		}
+	}
+
+	return nil
+}
+
+func validateVariable(kind string, v variable) error {
+	if rq, ok := v.(*rawQuery); ok {
+		return validateRawQuery(rq.value)
+	}
+
+	field := kind + " parameter"
+
+	if _, ok := v.(*pathSegment); ok {
+		field = "path segment"
+
+		if err := validatePathSegment(v.varName()); err != nil {
+			return &BuildError{field, v.varName(), err.Error()}
+		}
+	}
+
+	if len(v.varName()) == 0 {
+		return &BuildError{field, v.varName(), "name must not be empty"}
+	}
+
+	return nil
+}
+
+func validatePathSegment(s string) error {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return fmt.Errorf("contains invalid percent-encoding")
+			}
+
+			i += 2
+		case c <= ' ' || c >= 0x7f || strings.IndexByte("\"#<>?\\^`{|}", c) >= 0:
+			return fmt.Errorf("contains invalid character %q", c)
+		}
+	}
+
+	return nil
+}
+
+func validateRawQuery(query string) error {
+	for i := 0; i < len(query); i++ {
+		if c := query[i]; c <= ' ' || c >= 0x7f || c == '#' {
+			return &BuildError{"raw query", query, fmt.Sprintf("contains invalid character %q", c)}
+		}
+	}
+
+	return nil
+}
+
+func isTokenChar(r rune) bool {
+	return isAlpha(r) || isDigit(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+func isAlpha(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(rune(c)) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
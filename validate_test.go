@@ -0,0 +1,222 @@
+package currly_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestBuildRejectsInvalidURLParts(t *testing.T) {
+	cases := []struct {
+		field   string
+		builder currly.BuildCurl
+	}{
+		{"host", currly.Builder().GET().HTTPS().Host("")},
+		{"host", currly.Builder().GET().HTTPS().Host("api example.com")},
+		{"port", currly.Builder().GET().HTTPS().Localhost().Port(70000)},
+		{"path segment", currly.Builder().GET().HTTPS().Localhost().PathSegment("my users")},
+		{"path segment", currly.Builder().GET().HTTPS().Localhost().PathSegment("100%")},
+		{"method", currly.Builder().Method("GET ME").HTTPS().Localhost()},
+		{"host", currly.Builder().GET().HTTPS().Host("2001:db8::zz")},
+		{"scheme", currly.Builder().GET().Scheme("").Localhost()},
+		{"scheme", currly.Builder().GET().Scheme("1ws").Localhost()},
+	}
+
+	for _, c := range cases {
+		_, err := c.builder.Build()
+
+		var berr *currly.BuildError
+
+		if !errors.As(err, &berr) {
+			t.Errorf("Building an invalid %v should return a build error, got: %v", c.field, err)
+
+			continue
+		}
+
+		if c.field != berr.Field {
+			t.Errorf("Unexpected invalid field (expected: %v, actual: %v).", c.field, berr.Field)
+		}
+	}
+
+	if _, err := currly.Builder().GET().HTTPS().Host("api.example.com").Port(8443).PathSegment("v1%2Fusers").Build(); err != nil {
+		t.Errorf("Building a valid template returned an unexpected error: %v", err)
+	}
+}
+
+func TestHostConvertsInternationalizedNamesToPunycode(t *testing.T) {
+	var host string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		host = r.URL.Host
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Host("bücher.example").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "xn--bcher-kva.example" != host {
+		t.Errorf("Unexpected host (expected: %v, actual: %v).", "xn--bcher-kva.example", host)
+	}
+}
+
+func TestHostAcceptsIPv6Literals(t *testing.T) {
+	cases := []struct {
+		host string
+		port uint
+		url  string
+	}{
+		{"2001:db8::1", 8443, "https://[2001:db8::1]:8443/status"},
+		{"[2001:db8::1]", 0, "https://[2001:db8::1]/status"},
+		{"fe80::1%eth0", 0, "https://[fe80::1%25eth0]/status"},
+	}
+
+	for _, c := range cases {
+		var u string
+
+		con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+			u = r.URL.String()
+
+			return okResponse(r, ""), nil
+		})
+		curl, err := currly.Builder().GET().HTTPS().Host(c.host).Port(c.port).PathSegment("status").Build()
+
+		if err != nil {
+			t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+		}
+
+		if _, _, err = curl(con); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+
+		if c.url != u {
+			t.Errorf("Unexpected URL (expected: %v, actual: %v).", c.url, u)
+		}
+	}
+}
+
+func TestSchemeAcceptsCustomSchemes(t *testing.T) {
+	var u string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		u = r.URL.String()
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().Scheme("unix+http").Localhost().PathSegment("events").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "unix+http://localhost/events" != u {
+		t.Errorf("Unexpected URL (expected: %v, actual: %v).", "unix+http://localhost/events", u)
+	}
+}
+
+func TestValidatedArgAppliesRulesBeforeBinding(t *testing.T) {
+	var query string
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		query = r.URL.RawQuery
+
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathParam("id").QueryParam("sort").QueryParam("limit").Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		arg    currly.Arg
+		param  string
+		reason string
+	}{
+		{currly.ValidatedArg(currly.PathArg("id", ""), currly.NonEmpty()), "id", "must not be empty"},
+		{currly.ValidatedArg(currly.PathArg("id", "abc"), currly.MatchesPattern(`^[0-9]+$`)), "id", `must match "^[0-9]+$"`},
+		{currly.ValidatedArg(currly.QueryArg("sort", "random"), currly.OneOf("asc", "desc")), "sort", "must be one of asc, desc"},
+		{currly.ValidatedArg(currly.QueryArg("limit", "500"), currly.InRange(1, 100)), "limit", "must be between 1 and 100"},
+		{currly.ValidatedArg(currly.QueryArg("limit", "ten"), currly.InRange(1, 100)), "limit", "must be a number"},
+		{currly.ValidatedArg(currly.QueryArg("sort", "ascending"), currly.MaxLength(4)), "sort", "must not be longer than 4 characters"},
+		{currly.ValidatedArg(currly.HostHeaderArg("example.com"), currly.NonEmpty()), "", "argument does not bind a parameter value"},
+	}
+
+	for _, c := range cases {
+		_, _, err := curl(con, c.arg)
+
+		var be *currly.BindError
+
+		if !errors.As(err, &be) || c.param != be.Param || c.reason != be.Reason {
+			t.Errorf("Unexpected bind error (expected: %v: %v, actual: %v).", c.param, c.reason, err)
+		}
+	}
+
+	_, _, err = curl(con,
+		currly.ValidatedArg(currly.PathArg("id", "42"), currly.NonEmpty(), currly.MatchesPattern(`^[0-9]+$`)),
+		currly.ValidatedArg(currly.QueryArg("sort", "asc"), currly.OneOf("asc", "desc"), currly.MaxLength(4)),
+		currly.ValidatedArg(currly.QueryArg("limit", "10"), currly.InRange(1, 100)))
+
+	if err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	if "sort=asc&limit=10" != query {
+		t.Errorf("Unexpected query (expected: %v, actual: %v).", "sort=asc&limit=10", query)
+	}
+}
+
+func TestOnBindErrorReportsBindingFailures(t *testing.T) {
+	var events []currly.BindEvent
+
+	con := connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	})
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathSegment("users").PathParam("id").QueryParam("q").
+		Name("get-user").
+		OnBindError(func(e currly.BindEvent) { events = append(events, e) }).
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	if _, _, err = curl(con, currly.PathArg("id", "42"), currly.QueryArg("missing", "x")); err == nil {
+		t.Errorf("Expected an error for an unknown query parameter.")
+	}
+
+	curl(con, currly.ValidatedArg(currly.PathArg("id", "abc"), currly.MatchesPattern(`^[0-9]+$`)))
+	curl(con)
+
+	if _, _, err = curl(con, currly.PathArgExplicit("id", "")); err != nil {
+		t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+	}
+
+	expected := []currly.BindEvent{
+		{Template: "get-user", Param: "missing"},
+		{Template: "get-user", Param: "id"},
+		{Template: "get-user", Param: "id", Missing: true},
+	}
+
+	if len(expected) != len(events) {
+		t.Fatalf("Unexpected bind events (expected: %v, actual: %v).", len(expected), events)
+	}
+
+	for i, e := range expected {
+		if e.Template != events[i].Template || e.Param != events[i].Param || e.Missing != events[i].Missing || events[i].Err == nil {
+			t.Errorf("Unexpected bind event (expected: %+v, actual: %+v).", e, events[i])
+		}
+	}
+}
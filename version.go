@@ -0,0 +1,59 @@
+package currly
+
+import (
+	"log/slog"
+	"sync"
+)
+
+func (ct curlTemplate) Version(v string) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.version = v
+
+	return ct
+}
+
+func (ct curlTemplate) Deprecated(replacement string) SetResultExtractor {
+	if ct.error != nil {
+		return ct
+	}
+
+	ct.deprecation = &deprecation{replacement: replacement}
+
+	return ct
+}
+
+func (ci callInfo) deprecationAttrs() []slog.Attr {
+	attrs := []slog.Attr{slog.String("url_template", ci.urlTemplate)}
+
+	if len(ci.templateName) > 0 {
+		attrs = append(attrs, slog.String("template", ci.templateName))
+	}
+
+	if len(ci.version) > 0 {
+		attrs = append(attrs, slog.String("api_version", ci.version))
+	}
+
+	if len(ci.deprecation.replacement) > 0 {
+		attrs = append(attrs, slog.String("replacement", ci.deprecation.replacement))
+	}
+
+	return attrs
+}
+
+type deprecation struct {
+	replacement string
+	once        sync.Once
+}
+
+func (d *deprecation) warn() bool {
+	warned := false
+
+	d.once.Do(func() {
+		warned = true
+	})
+
+	return warned
+}
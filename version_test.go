@@ -0,0 +1,72 @@
+package currly_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/DrDoofenshmirtz/currly"
+)
+
+func TestDeprecatedTemplateWarnsOnce(t *testing.T) {
+	buf := new(bytes.Buffer)
+	con := currly.Wrap(connectorFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r, ""), nil
+	}), currly.LoggingMiddleware(slog.New(slog.NewJSONHandler(buf, nil))))
+	curl, err := currly.Builder().GET().HTTPS().Localhost().PathSegment("v1").PathSegment("users").
+		Name("users.list").
+		Version("v1").
+		Deprecated("users.listV2").
+		Build()
+
+	if err != nil {
+		t.Fatalf("Building the cURL function returned an unexpected error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := curl(con); err != nil {
+			t.Fatalf("Calling the cURL function returned an unexpected error: %v", err)
+		}
+	}
+
+	var warnings, requests []map[string]interface{}
+
+	for s := bufio.NewScanner(buf); s.Scan(); {
+		var rec map[string]interface{}
+
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			t.Fatalf("The log record should be valid JSON: %v", err)
+		}
+
+		if "currly deprecated template" == rec["msg"] {
+			warnings = append(warnings, rec)
+		} else {
+			requests = append(requests, rec)
+		}
+	}
+
+	if 1 != len(warnings) || 3 != len(requests) {
+		t.Fatalf("Unexpected log records (expected: 1 warning and 3 requests, actual: %v and %v).", len(warnings), len(requests))
+	}
+
+	expected := map[string]interface{}{
+		"level":        "WARN",
+		"template":     "users.list",
+		"url_template": "https://localhost/v1/users",
+		"api_version":  "v1",
+		"replacement":  "users.listV2",
+	}
+
+	for k, v := range expected {
+		if v != warnings[0][k] {
+			t.Errorf("Unexpected warning attribute '%v' (expected: %v, actual: %v).", k, v, warnings[0][k])
+		}
+	}
+
+	if "v1" != requests[0]["api_version"] {
+		t.Errorf("Unexpected request attribute 'api_version' (expected: v1, actual: %v).", requests[0]["api_version"])
+	}
+}